@@ -0,0 +1,25 @@
+package sqlbuilder
+
+import "strconv"
+
+// ArrayIndex returns "col[i]" pg array element access expression.
+//
+// ArrayIndex panics if driver is not pg.
+func (q *Query) ArrayIndex(col string, i int) string {
+	if q.driver != "pg" {
+		panic("sqlbuilder.ArrayIndex: unsupported by driver: " + q.driver)
+	}
+	q.setExprSpecific()
+	return col + "[" + strconv.Itoa(i) + "]"
+}
+
+// ArraySlice returns "col[lo:hi]" pg array slice expression.
+//
+// ArraySlice panics if driver is not pg.
+func (q *Query) ArraySlice(col string, lo, hi int) string {
+	if q.driver != "pg" {
+		panic("sqlbuilder.ArraySlice: unsupported by driver: " + q.driver)
+	}
+	q.setExprSpecific()
+	return col + "[" + strconv.Itoa(lo) + ":" + strconv.Itoa(hi) + "]"
+}
@@ -0,0 +1,22 @@
+package sqlbuilder
+
+import "testing"
+
+func TestArray(t *testing.T) {
+	q := NewQuery("posts")
+	q.Select(q.ArraySlice("tags", 1, 3)).Where(q.ArrayIndex("tags", 1)+" = ?", "go")
+
+	gotStr := q.String()
+	wantStr := "SELECT tags[1:3] FROM posts WHERE tags[1] = $1"
+
+	if gotStr != wantStr {
+		t.Errorf("Array string: want %q, got %q", wantStr, gotStr)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("ArrayIndex on mysql: want panic")
+		}
+	}()
+	NewQuery("posts").SetDriver("mysql").ArrayIndex("tags", 1)
+}
@@ -0,0 +1,43 @@
+package sqlbuilder
+
+// Batch describes multiple sql statements joined by semicolons.
+//
+// pg placeholders of added statements are renumbered so they're unique
+// across the batch and follow the combined arguments order,
+// '?' placeholders are kept as is and their arguments are appended in order.
+type Batch struct {
+	q *Query
+}
+
+// NewBatch returns new Batch with stmts.
+func NewBatch(stmts ...*Statement) *Batch {
+	b := &Batch{q: NewQuery()}
+	for _, s := range stmts {
+		b.Add(s)
+	}
+	return b
+}
+
+// Add appends s to batch.
+func (b *Batch) Add(s *Statement) *Batch {
+	if b.q.str.Len() == 0 {
+		b.q.SetDriver(s.driver)
+	} else {
+		b.q.str.WriteString("; ")
+	}
+	b.q.embed(s.Query)
+	if s.terminate {
+		b.q.terminate = true
+	}
+	return b
+}
+
+// String returns batch string.
+func (b *Batch) String() string {
+	return b.q.String()
+}
+
+// Args returns batch combined arguments.
+func (b *Batch) Args() []interface{} {
+	return b.q.Args()
+}
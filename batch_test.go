@@ -0,0 +1,55 @@
+package sqlbuilder
+
+import "testing"
+
+func TestBatch(t *testing.T) {
+	b := NewBatch(
+		NewQuery("users").Update("name = ?", "n1").Where("id = ?", 1),
+		NewQuery("logs").Insert([]string{"user_id", "action"}, 1, "rename"),
+	)
+
+	gotStr := b.String()
+	wantStr := "UPDATE users SET name = $1 WHERE id = $2; INSERT INTO logs(user_id,action) VALUES ($3,$4)"
+	gotArgs := b.Args()
+	wantArgs := []interface{}{"n1", 1, 1, "rename"}
+
+	if gotStr != wantStr {
+		t.Errorf("Batch string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Batch arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Batch arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	b = NewBatch(
+		NewQuery("users").SetDriver("mysql").Delete().Where("id = ?", 1),
+		NewQuery("logs").SetDriver("mysql").Delete().Where("user_id = ?", 1),
+	)
+
+	gotStr = b.String()
+	wantStr = "DELETE FROM users WHERE id = ?; DELETE FROM logs WHERE user_id = ?"
+
+	if gotStr != wantStr {
+		t.Errorf("Batch mysql string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(b.Args()) != 2 {
+		t.Errorf("Batch mysql arguments length: want 2, got %d", len(b.Args()))
+	}
+}
+
+func TestBatchTerminate(t *testing.T) {
+	q1 := NewQuery("a").SetTerminate(true)
+	q2 := NewQuery("b").SetTerminate(true)
+	b := NewBatch(q1.Delete().Where("id = ?", 1), q2.Delete().Where("id = ?", 2))
+
+	gotStr := b.String()
+	wantStr := "DELETE FROM a WHERE id = $1; DELETE FROM b WHERE id = $2;"
+
+	if gotStr != wantStr {
+		t.Errorf("Batch terminated string: want %q, got %q", wantStr, gotStr)
+	}
+}
@@ -0,0 +1,74 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Compiled describes a built sql statement.
+//
+// Unlike Query, Compiled is a value safe to store, pass around and compare,
+// it's not affected by further changes to the query it was compiled from.
+type Compiled struct {
+	SQL  string
+	Args []interface{}
+	Kind Kind
+	Err  error // error of the query or of compiling for another driver.
+}
+
+// Compile returns Compiled snapshot of query string, arguments and kind.
+func (q *Query) Compile() Compiled {
+	var args []interface{}
+	if q.args != nil {
+		args = make([]interface{}, len(q.args))
+		copy(args, q.args)
+	}
+	return Compiled{SQL: q.String(), Args: args, Kind: q.kind, Err: q.err}
+}
+
+// Dialect returns Compiled snapshot of statement with placeholders
+// written for driver, statement's query driver is not changed,
+// e.g. a pg statement "a = $1" compiles to "a = ?" for mysql.
+//
+// Only placeholders are converted, so Dialect sets the error of Compiled,
+// leaving query error as is, if driver differs from query driver
+// and statement has sql specific to query driver,
+// e.g. WhereTrue, WhereJSONEq, Returning, OnConflictDoUpdate, Offset without Limit,
+// identifiers quoted by QuoteIdent, create, alter and drop statements,
+// a limit compiled from or to mssql or a parenthesized union compiled for sqlite.
+// Sql written as is, e.g. raw conditions or Greatest, isn't checked.
+func (s *Statement) Dialect(driver string) Compiled {
+	d, ok := dialects[driverName(driver)]
+	if !ok {
+		panic("sqlbuilder.Dialect: unsupported driver: " + driver)
+	}
+	c := s.Compile()
+	if c.Err == nil && d.name != s.driver && s.dialectSpecific(d.name) {
+		c.Err = fmt.Errorf("sqlbuilder.Dialect: statement has sql specific to driver %s, can't compile for %s", s.driver, d.name)
+	}
+	if p := d.prefix; p != s.dialect().prefix {
+		c.SQL = replacePlaceholders(c.SQL, s.dialect().prefix, func(n int) string {
+			if p == "" {
+				return "?"
+			}
+			return p + strconv.Itoa(n)
+		})
+	}
+	return c
+}
+
+// dialectSpecific reports whether statement has sql that isn't valid for driver.
+func (s *Statement) dialectSpecific(driver string) bool {
+	switch s.kind {
+	case KindCreate, KindAlter, KindDrop:
+		return true
+	}
+	if s.specific {
+		return true
+	}
+	if driver == "sqlite" && s.clauses&clauseUnion != 0 {
+		return true
+	}
+	limited := s.clauses&(clauseLimit|clauseOffset) != 0 || s.rowsCap() != ""
+	return limited && (s.driver == "mssql" || driver == "mssql")
+}
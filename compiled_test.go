@@ -0,0 +1,103 @@
+package sqlbuilder
+
+import "testing"
+
+func TestCompile(t *testing.T) {
+	q := NewQuery("test")
+	c := q.Select("id").Where("id = ?", 1).Compile()
+	q.Delete().Where("id = ?", 2)
+
+	wantStr := "SELECT id FROM test WHERE id = $1"
+
+	if c.SQL != wantStr {
+		t.Errorf("Compile string: want %q, got %q", wantStr, c.SQL)
+	}
+	if len(c.Args) != 1 || c.Args[0] != 1 {
+		t.Errorf("Compile arguments: want [1], got %v", c.Args)
+	}
+	if c.Kind != KindSelect {
+		t.Errorf("Compile kind: want %v, got %v", KindSelect, c.Kind)
+	}
+
+	q.Args()[0] = 3
+	if c.Args[0] != 1 {
+		t.Errorf("Compile arguments after query change: want [1], got %v", c.Args)
+	}
+}
+
+func TestStatementDialect(t *testing.T) {
+	q := NewQuery("users")
+	s := q.Select("id").Where("name = ? AND note <> '?'", "bob").And("age > ?", 18)
+
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{"pg", "SELECT id FROM users WHERE name = $1 AND note <> '?' AND age > $2"},
+		{"mysql", "SELECT id FROM users WHERE name = ? AND note <> '?' AND age > ?"},
+		{"mssql", "SELECT id FROM users WHERE name = @p1 AND note <> '?' AND age > @p2"},
+	}
+	for _, tt := range tests {
+		c := s.Dialect(tt.driver)
+		if c.SQL != tt.want {
+			t.Errorf("Dialect %s string: want %q, got %q", tt.driver, tt.want, c.SQL)
+		}
+		if len(c.Args) != 2 || c.Args[0] != "bob" || c.Args[1] != 18 {
+			t.Errorf("Dialect %s arguments: want [bob 18], got %v", tt.driver, c.Args)
+		}
+	}
+	if q.Driver() != "pg" {
+		t.Errorf("Dialect query driver: want %q, got %q", "pg", q.Driver())
+	}
+
+	q = NewQuery("users").SetDriver("mysql")
+	c := q.Select("id").Where("id = ?", 1).Dialect("postgres")
+	if want := "SELECT id FROM users WHERE id = $1"; c.SQL != want {
+		t.Errorf("Dialect mysql to pg string: want %q, got %q", want, c.SQL)
+	}
+
+	c = q.Select("`who?`").Where("id = ?", 1).Dialect("pg")
+	if want := "SELECT `who?` FROM users WHERE id = $1"; c.SQL != want {
+		t.Errorf("Dialect backtick identifier string: want %q, got %q", want, c.SQL)
+	}
+}
+
+func TestStatementDialectSpecific(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       func(q *Query) *Statement
+		driver  string
+		wantErr bool
+	}{
+		{"Where", func(q *Query) *Statement { return q.Select("id").Where("id = ?", 1) }, "mysql", false},
+		{"Limit", func(q *Query) *Statement { return q.Select("id").Limit(10).Offset(20) }, "sqlite", false},
+		{"Limit to mssql", func(q *Query) *Statement { return q.Select("id").Limit(10) }, "mssql", true},
+		{"rows cap to mssql", func(q *Query) *Statement { return q.SetMaxRows(10).Select("id") }, "mssql", true},
+		{"Offset", func(q *Query) *Statement { return q.Select("id").Offset(20) }, "mysql", true},
+		{"WhereTrue", func(q *Query) *Statement { return q.Select("id").WhereTrue("active") }, "mysql", true},
+		{"WhereTrue same driver", func(q *Query) *Statement { return q.Select("id").WhereTrue("active") }, "postgres", false},
+		{"WhereJSONEq", func(q *Query) *Statement { return q.Select("id").WhereJSONEq("data", "a.b", 1) }, "sqlite", true},
+		{"Returning", func(q *Query) *Statement { return q.Delete().Where("id = ?", 1).Returning("id") }, "sqlite", true},
+		{"OnConflictDoNothing", func(q *Query) *Statement { return q.Insert([]string{"id"}, 1).OnConflictDoNothing("id") }, "mysql", true},
+		{"QuoteIdent", func(q *Query) *Statement { return q.Select(q.QuoteIdent("order")) }, "mysql", true},
+		{"DropTable", func(q *Query) *Statement { return q.Confirm().DropTable(false, false) }, "mysql", true},
+		{"sub query", func(q *Query) *Statement { return q.SelectExists(NewQuery("users").Select("id").WhereTrue("active")) }, "mysql", true},
+		{"Union", func(q *Query) *Statement { return q.Select("id").Union(NewQuery("admins").Select("id")) }, "mysql", false},
+		{"Union to sqlite", func(q *Query) *Statement { return q.Select("id").Union(NewQuery("admins").Select("id")) }, "sqlite", true},
+	}
+	for _, tt := range tests {
+		s := tt.s(NewQuery("users"))
+		if err := s.Dialect(tt.driver).Err; (err != nil) != tt.wantErr {
+			t.Errorf("Dialect %s to %s error: want error %v, got %v", tt.name, tt.driver, tt.wantErr, err)
+		}
+		if err := s.Err(); err != nil {
+			t.Errorf("Dialect %s to %s query error: want <nil>, got %v", tt.name, tt.driver, err)
+		}
+	}
+
+	q := NewQuery("users")
+	q.Select(q.QuoteIdent("order"))
+	if err := q.Select("id").Dialect("mysql").Err; err != nil {
+		t.Errorf("Dialect after QuoteIdent in previous statement: unexpected error: %v", err)
+	}
+}
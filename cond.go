@@ -0,0 +1,233 @@
+package sqlbuilder
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Cond describes a reusable sql condition.
+//
+// Placeholders in a Cond are written as '?' and renumbered
+// when the condition is applied to a query, so the same Cond
+// can be used with any driver and at any argument offset.
+type Cond struct {
+	str   string
+	args  []interface{}
+	op    string
+	conds []Cond
+}
+
+// C returns a raw Cond from str and args.
+func C(str string, args ...interface{}) Cond {
+	return Cond{str: str, args: args}
+}
+
+// Eq returns "column = ?" Cond.
+func Eq(column string, value interface{}) Cond {
+	return C(column+" = ?", value)
+}
+
+// Ne returns "column <> ?" Cond.
+func Ne(column string, value interface{}) Cond {
+	return C(column+" <> ?", value)
+}
+
+// Gt returns "column > ?" Cond.
+func Gt(column string, value interface{}) Cond {
+	return C(column+" > ?", value)
+}
+
+// Gte returns "column >= ?" Cond.
+func Gte(column string, value interface{}) Cond {
+	return C(column+" >= ?", value)
+}
+
+// Lt returns "column < ?" Cond.
+func Lt(column string, value interface{}) Cond {
+	return C(column+" < ?", value)
+}
+
+// Lte returns "column <= ?" Cond.
+func Lte(column string, value interface{}) Cond {
+	return C(column+" <= ?", value)
+}
+
+// In returns "column IN (?,...)" Cond with a placeholder per element of values,
+// values must be a slice or an array.
+// In with no values returns a Cond that's always false.
+//
+// In panics if values is not a slice or an array.
+func In(column string, values interface{}) Cond {
+	args := sliceArgs("sqlbuilder.In", values)
+	if len(args) == 0 {
+		return C("1=0")
+	}
+	return C(column+" IN ("+placeholders(len(args))+")", args...)
+}
+
+// sliceArgs returns elements of values, panics if values is not a slice or an array.
+func sliceArgs(caller string, values interface{}) []interface{} {
+	if args, ok := values.([]interface{}); ok {
+		return args
+	}
+	v := reflect.ValueOf(values)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		panic(caller + ": values must be a slice or an array")
+	}
+	args := make([]interface{}, v.Len())
+	for i := range args {
+		args[i] = v.Index(i).Interface()
+	}
+	return args
+}
+
+// placeholders returns n comma separated '?' placeholders.
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat("?,", n-1) + "?"
+}
+
+// And returns Cond joining conds with AND.
+func And(conds ...Cond) Cond {
+	return Cond{op: " AND ", conds: conds}
+}
+
+// Or returns Cond joining conds with OR.
+func Or(conds ...Cond) Cond {
+	return Cond{op: " OR ", conds: conds}
+}
+
+// Not returns "NOT (c)" Cond.
+func Not(c Cond) Cond {
+	return Cond{op: notOp, conds: []Cond{c}}
+}
+
+const notOp = "NOT"
+
+func (c Cond) isGroup() bool {
+	return c.op != ""
+}
+
+// apply writes c to q, nested groups are parenthesized.
+func (c Cond) apply(q *Query) {
+	if !c.isGroup() {
+		q.Raw(c.str, c.args...)
+		return
+	}
+	if c.op == notOp {
+		q.str.WriteString("NOT (")
+		c.conds[0].apply(q)
+		q.str.WriteByte(')')
+		return
+	}
+	for i, cc := range c.conds {
+		if i != 0 {
+			q.str.WriteString(c.op)
+		}
+		if cc.isGroup() && len(cc.conds) > 1 {
+			q.str.WriteByte('(')
+			cc.apply(q)
+			q.str.WriteByte(')')
+		} else {
+			cc.apply(q)
+		}
+	}
+}
+
+// WhereBuilder builds a Cond of conditions joined by AND,
+// it's passed to the callback given to Statement.Where.
+type WhereBuilder struct {
+	conds []Cond
+}
+
+// Cond returns the built Cond.
+func (w *WhereBuilder) Cond() Cond {
+	return And(w.conds...)
+}
+
+// Add adds c to builder.
+func (w *WhereBuilder) Add(c Cond) *WhereBuilder {
+	w.conds = append(w.conds, c)
+	return w
+}
+
+// Raw adds raw condition to builder.
+func (w *WhereBuilder) Raw(str string, args ...interface{}) *WhereBuilder {
+	return w.Add(C(str, args...))
+}
+
+// Eq adds "column = ?" condition to builder.
+func (w *WhereBuilder) Eq(column string, value interface{}) *WhereBuilder {
+	return w.Add(Eq(column, value))
+}
+
+// Ne adds "column <> ?" condition to builder.
+func (w *WhereBuilder) Ne(column string, value interface{}) *WhereBuilder {
+	return w.Add(Ne(column, value))
+}
+
+// Gt adds "column > ?" condition to builder.
+func (w *WhereBuilder) Gt(column string, value interface{}) *WhereBuilder {
+	return w.Add(Gt(column, value))
+}
+
+// Gte adds "column >= ?" condition to builder.
+func (w *WhereBuilder) Gte(column string, value interface{}) *WhereBuilder {
+	return w.Add(Gte(column, value))
+}
+
+// Lt adds "column < ?" condition to builder.
+func (w *WhereBuilder) Lt(column string, value interface{}) *WhereBuilder {
+	return w.Add(Lt(column, value))
+}
+
+// Lte adds "column <= ?" condition to builder.
+func (w *WhereBuilder) Lte(column string, value interface{}) *WhereBuilder {
+	return w.Add(Lte(column, value))
+}
+
+// In adds "column IN (?,...)" condition to builder.
+func (w *WhereBuilder) In(column string, values interface{}) *WhereBuilder {
+	return w.Add(In(column, values))
+}
+
+// And adds a group of the conditions built by fn joined by AND.
+func (w *WhereBuilder) And(fn func(w *WhereBuilder)) *WhereBuilder {
+	var g WhereBuilder
+	fn(&g)
+	return w.Add(And(g.conds...))
+}
+
+// Or adds a group of the conditions built by fn joined by OR.
+func (w *WhereBuilder) Or(fn func(w *WhereBuilder)) *WhereBuilder {
+	var g WhereBuilder
+	fn(&g)
+	return w.Add(Or(g.conds...))
+}
+
+// Filter describes a reusable where clause of conditions joined by AND,
+// applying the same Filter to a select and its count keeps them in sync.
+type Filter struct {
+	conds []Cond
+}
+
+// NewFilter returns new Filter with conds.
+func NewFilter(conds ...Cond) *Filter {
+	return &Filter{conds: conds}
+}
+
+// Add adds c to filter.
+func (f *Filter) Add(c Cond) *Filter {
+	f.conds = append(f.conds, c)
+	return f
+}
+
+// Empty reports whether filter has no conditions.
+func (f *Filter) Empty() bool {
+	return len(f.conds) == 0
+}
@@ -0,0 +1,214 @@
+package sqlbuilder
+
+import "testing"
+
+func TestCond(t *testing.T) {
+	q := NewQuery("test")
+	q.Update("t1 = ?", "v1").Where(And(
+		Eq("id", 5),
+		Or(Gt("age", 18), Lte("score", 10.5)),
+		C("name <> ?", "x"),
+	))
+
+	gotStr := q.String()
+	wantStr := "UPDATE test SET t1 = $1 WHERE id = $2 AND (age > $3 OR score <= $4) AND name <> $5"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{"v1", 5, 18, 10.5, "x"}
+
+	if gotStr != wantStr {
+		t.Errorf("Cond string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Cond arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Cond arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q = NewQuery("test").SetDriver("mysql")
+	q.Select().Where(Or(Eq("a", 1), And(Ne("b", 2), Lt("c", 3))))
+
+	gotStr = q.String()
+	wantStr = "SELECT * FROM test WHERE a = ? OR (b <> ? AND c < ?)"
+	gotArgs = q.Args()
+	wantArgs = []interface{}{1, 2, 3}
+
+	if gotStr != wantStr {
+		t.Errorf("Cond mysql string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Cond mysql arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Cond mysql arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+}
+
+func TestHavingCond(t *testing.T) {
+	q := NewQuery("test")
+	q.Select("category", "COUNT(*)").Where("active = ?", true).GroupBy("category").Having(Gt("COUNT(*)", 5))
+
+	gotStr := q.String()
+	wantStr := "SELECT category,COUNT(*) FROM test WHERE active = $1 GROUP BY category HAVING COUNT(*) > $2"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{true, 5}
+
+	if gotStr != wantStr {
+		t.Errorf("Having string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Having arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Having arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+}
+
+func TestWhereBuilder(t *testing.T) {
+	q := NewQuery("users")
+	q.Select("id").Where(func(w *WhereBuilder) {
+		w.Eq("active", true).In("role", []string{"admin", "owner"})
+		w.Or(func(w *WhereBuilder) {
+			w.Gt("age", 18).Eq("verified", true)
+		})
+	})
+
+	gotStr := q.String()
+	wantStr := "SELECT id FROM users WHERE active = $1 AND role IN ($2,$3) AND (age > $4 OR verified = $5)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{true, "admin", "owner", 18, true}
+
+	if gotStr != wantStr {
+		t.Errorf("WhereBuilder string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("WhereBuilder arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("WhereBuilder arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	f := NewFilter(Eq("status", "active")).Add(Gt("age", 18))
+
+	list := NewQuery("users").Select("id", "name").Apply(f).OrderBy("id").Limit(10)
+	count := NewQuery("users").Count().Apply(f)
+
+	wantList := "SELECT id,name FROM users WHERE status = $1 AND age > $2 ORDER BY id LIMIT $3"
+	wantCount := "SELECT COUNT(*) FROM users WHERE status = $1 AND age > $2"
+
+	if got := list.String(); got != wantList {
+		t.Errorf("Filter select string: want %q, got %q", wantList, got)
+	}
+	if got := count.String(); got != wantCount {
+		t.Errorf("Filter count string: want %q, got %q", wantCount, got)
+	}
+	for i, v := range count.Args() {
+		if v != list.Args()[i] {
+			t.Errorf("Filter arguments[%d]: select %v, count %v", i, list.Args()[i], v)
+		}
+	}
+
+	if got, want := NewQuery("users").Count().Apply(NewFilter()).String(), "SELECT COUNT(*) FROM users"; got != want {
+		t.Errorf("Empty Filter string: want %q, got %q", want, got)
+	}
+}
+
+func TestNot(t *testing.T) {
+	q := NewQuery("users")
+	q.Select("id").Not("status = ? OR banned", "inactive")
+
+	gotStr := q.String()
+	wantStr := "SELECT id FROM users WHERE NOT (status = $1 OR banned)"
+
+	if gotStr != wantStr {
+		t.Errorf("Not string: want %q, got %q", wantStr, gotStr)
+	}
+	if args := q.Args(); len(args) != 1 || args[0] != "inactive" {
+		t.Errorf("Not arguments: want [inactive], got %v", args)
+	}
+
+	q.Select("id").Where(And(Eq("a", 1), Not(Or(Eq("b", 2), Eq("c", 3)))))
+
+	gotStr = q.String()
+	wantStr = "SELECT id FROM users WHERE a = $1 AND NOT (b = $2 OR c = $3)"
+
+	if gotStr != wantStr {
+		t.Errorf("Not Cond string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestAndOrChain(t *testing.T) {
+	q := NewQuery("users")
+	q.Select("id").Where("a = ?", 1).Or("b = ?", 2).Or("c = ?", 3).And("d = ?", 4).Or(Eq("e", 5)).And(Or(Eq("f", 6), Eq("g", 7)))
+
+	gotStr := q.String()
+	wantStr := "SELECT id FROM users WHERE ((a = $1 OR b = $2 OR c = $3) AND d = $4 OR e = $5) AND (f = $6 OR g = $7)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{1, 2, 3, 4, 5, 6, 7}
+
+	if gotStr != wantStr {
+		t.Errorf("And Or chain string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("And Or chain arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("And Or chain arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	if got, want := q.Select("id").Or("a = ?", 1).And("b = ?", 2).String(), "SELECT id FROM users WHERE a = $1 AND b = $2"; got != want {
+		t.Errorf("Or without where string: want %q, got %q", want, got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Or after order by: want panic")
+		}
+	}()
+	q.Select("id").Where("a = ?", 1).OrderBy("id").Or("b = ?", 2)
+}
+
+func TestAndOrAfterClauses(t *testing.T) {
+	tests := []struct {
+		name   string
+		clause func(s *Statement)
+	}{
+		{"GroupBy", func(s *Statement) { s.GroupBy("a") }},
+		{"Having", func(s *Statement) { s.GroupBy("a").Having("COUNT(*) > ?", 1) }},
+		{"Offset", func(s *Statement) { s.Limit(10).Offset(20) }},
+		{"Returning", func(s *Statement) { s.Returning("id") }},
+	}
+	for _, tt := range tests {
+		for _, op := range []string{"And", "Or"} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("%s after %s: want panic", op, tt.name)
+					}
+				}()
+				s := NewQuery("users").Select("id").Where("a = ?", 1)
+				if tt.name == "Returning" {
+					s = NewQuery("users").Delete().Where("a = ?", 1)
+				}
+				tt.clause(s)
+				if op == "And" {
+					s.And("b = ?", 2)
+				} else {
+					s.Or("b = ?", 2)
+				}
+			}()
+		}
+	}
+}
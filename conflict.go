@@ -0,0 +1,176 @@
+package sqlbuilder
+
+// OnConflictDoNothing adds "ON CONFLICT (columns) DO NOTHING" to insert statement,
+// columns can be empty to match any conflict.
+// It's written before RETURNING if the statement already has one.
+//
+// OnConflictDoNothing panics if the driver doesn't support ON CONFLICT.
+func (s *Statement) OnConflictDoNothing(columns ...string) *Statement {
+	if !s.dialect().SupportsOnConflict() {
+		panic("sqlbuilder.OnConflictDoNothing: unsupported by driver: " + s.driver)
+	}
+	s.setSpecific()
+	tail := s.beforeReturning()
+	s.addConflictTarget(columns)
+	s.str.WriteString(" DO NOTHING")
+	s.restoreReturning(tail)
+	return s
+}
+
+// OnConflictDoUpdate adds "ON CONFLICT (columns) DO UPDATE SET data" to insert statement,
+// or "ON DUPLICATE KEY UPDATE data" for mysql where columns are ignored.
+// data type can be string or map[string]interface{} like in Update.
+// It's written before RETURNING if the statement already has one.
+//
+// OnConflictDoUpdate panics if the driver supports neither
+// or if columns is empty and driver is not mysql.
+func (s *Statement) OnConflictDoUpdate(columns []string, data interface{}, args ...interface{}) *Statement {
+	tail := s.beforeReturning()
+	s.addConflictUpdate("sqlbuilder.OnConflictDoUpdate", columns)
+	s.addSet("sqlbuilder.OnConflictDoUpdate", data, args...)
+	s.restoreReturning(tail)
+	return s
+}
+
+// UpsertStruct returns insert statement of record's columns and values
+// updating every column but conflictColumns on conflict,
+// with "col = EXCLUDED.col" or "col = VALUES(col)" for mysql.
+// Columns are the "db" tag or the lowercased field name of exported fields,
+// fields tagged "-" are skipped.
+// If query has no tables the table is derived from record like in InsertStruct.
+// If every column is a conflict column the conflict does nothing.
+//
+// UpsertStruct panics if record is not a struct or a pointer to a struct
+// or if conflictColumns is empty and driver is not mysql.
+func (q *Query) UpsertStruct(record interface{}, conflictColumns []string) *Statement {
+	cols, values := structValues("sqlbuilder.UpsertStruct", record)
+	defer q.useStructTable(record)()
+
+	keys := make(map[string]bool, len(conflictColumns))
+	for _, c := range conflictColumns {
+		keys[c] = true
+	}
+	update := make([]string, 0, len(cols))
+	for _, c := range cols {
+		if !keys[c] {
+			update = append(update, c)
+		}
+	}
+
+	s := q.insertRow(cols, values)
+	if len(update) == 0 {
+		if q.driver != "mysql" {
+			return s.OnConflictDoNothing(conflictColumns...)
+		}
+		s.addConflictUpdate("sqlbuilder.UpsertStruct", nil)
+		s.addAssign(cols[0], Expr(cols[0]))
+		return s
+	}
+	s.addConflictUpdate("sqlbuilder.UpsertStruct", conflictColumns)
+	for i, c := range update {
+		if i != 0 {
+			s.addComma()
+		}
+		if q.driver == "mysql" {
+			s.addAssign(c, Expr("VALUES("+c+")"))
+		} else {
+			s.addAssign(c, Expr("EXCLUDED."+c))
+		}
+	}
+	return s
+}
+
+// addConflictUpdate writes "ON CONFLICT (columns) DO UPDATE SET "
+// or "ON DUPLICATE KEY UPDATE " for mysql,
+// panics if the driver supports neither or if columns is empty
+// since DO UPDATE requires a conflict target.
+func (s *Statement) addConflictUpdate(caller string, columns []string) {
+	s.setSpecific()
+	switch {
+	case s.dialect().SupportsOnConflict():
+		if len(columns) == 0 {
+			panic(caller + ": conflict columns cannot be empty")
+		}
+		s.addConflictTarget(columns)
+		s.str.WriteString(" DO UPDATE SET ")
+	case s.driver == "mysql":
+		s.str.WriteString(" ON DUPLICATE KEY UPDATE ")
+	default:
+		panic(caller + ": unsupported by driver: " + s.driver)
+	}
+}
+
+// ConflictWhere makes the next OnConflictDoNothing or OnConflictDoUpdate
+// target a partial unique index with predicate,
+// e.g. "ON CONFLICT (email) WHERE active DO NOTHING".
+// Unique constraints with NULLS NOT DISTINCT (pg 15+) need no predicate,
+// they're targeted by their columns.
+//
+// ConflictWhere panics if the driver doesn't support ON CONFLICT.
+func (s *Statement) ConflictWhere(predicate string) *Statement {
+	if !s.dialect().SupportsOnConflict() {
+		panic("sqlbuilder.ConflictWhere: unsupported by driver: " + s.driver)
+	}
+	s.conflictWhere = predicate
+	return s
+}
+
+func (s *Statement) addConflictTarget(columns []string) {
+	if s.selectWhere {
+		s.str.WriteString(" WHERE true")
+		s.selectWhere = false
+	}
+	s.str.WriteString(" ON CONFLICT")
+	if len(columns) > 0 {
+		s.str.WriteString(" (")
+		s.addColumns(columns...)
+		s.str.WriteByte(')')
+	}
+	if s.conflictWhere != "" {
+		if len(columns) == 0 {
+			panic("sqlbuilder.ConflictWhere: conflict columns cannot be empty")
+		}
+		s.str.WriteString(" WHERE ")
+		s.str.WriteString(s.conflictWhere)
+		s.conflictWhere = ""
+	}
+}
+
+// beforeReturning removes RETURNING clause from query string and returns it,
+// so clauses that must precede it can be written first.
+// RETURNING clause has no arguments so placeholders numbering isn't affected.
+func (s *Statement) beforeReturning() string {
+	if s.returningAt < 0 {
+		return ""
+	}
+	tail := s.truncate(s.returningAt)
+	s.returningAt = -1
+	s.clauses &^= clauseReturning
+	return tail
+}
+
+// restoreReturning writes back RETURNING clause removed by beforeReturning.
+func (s *Statement) restoreReturning(tail string) {
+	if tail != "" {
+		s.returningAt = s.str.Len()
+		s.writeClause(clauseReturning, tail)
+	}
+}
+
+// ReturningXmax adds "(xmax = 0) AS inserted" to sql returning of query,
+// it's true if an upsert inserted the row and false if it updated it.
+//
+// ReturningXmax panics if driver is not pg.
+func (s *Statement) ReturningXmax() *Statement {
+	if s.driver != "pg" {
+		panic("sqlbuilder.ReturningXmax: unsupported by driver: " + s.driver)
+	}
+	s.setSpecific()
+	const inserted = "(xmax = 0) AS inserted"
+	if s.returningAt >= 0 {
+		s.addComma()
+		s.str.WriteString(inserted)
+		return s
+	}
+	return s.Returning(inserted)
+}
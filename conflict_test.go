@@ -0,0 +1,256 @@
+package sqlbuilder
+
+import "testing"
+
+func TestOnConflictReturning(t *testing.T) {
+	q := NewQuery("users")
+	q.Insert([]string{"email", "name"}, "a@b.c", "n1").
+		Returning("id").
+		OnConflictDoUpdate([]string{"email"}, "name = ?", "n2")
+
+	gotStr := q.String()
+	wantStr := "INSERT INTO users(email,name) VALUES ($1,$2) ON CONFLICT (email) DO UPDATE SET name = $3 RETURNING id"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{"a@b.c", "n1", "n2"}
+
+	if gotStr != wantStr {
+		t.Errorf("OnConflictDoUpdate string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("OnConflictDoUpdate arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("OnConflictDoUpdate arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q.Insert([]string{"email"}, "a@b.c").OnConflictDoNothing().Returning("id")
+
+	gotStr = q.String()
+	wantStr = "INSERT INTO users(email) VALUES ($1) ON CONFLICT DO NOTHING RETURNING id"
+
+	if gotStr != wantStr {
+		t.Errorf("OnConflictDoNothing string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.SetDriver("mysql").Insert([]string{"email", "name"}, "a@b.c", "n1").OnConflictDoUpdate(nil, "name = ?", "n2")
+
+	gotStr = q.String()
+	wantStr = "INSERT INTO users(email,name) VALUES (?,?) ON DUPLICATE KEY UPDATE name = ?"
+
+	if gotStr != wantStr {
+		t.Errorf("OnConflictDoUpdate mysql string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestReturningXmax(t *testing.T) {
+	q := NewQuery("users")
+	q.Insert([]string{"email"}, "a@b.c").OnConflictDoUpdate([]string{"email"}, "email = EXCLUDED.email").ReturningXmax()
+
+	gotStr := q.String()
+	wantStr := "INSERT INTO users(email) VALUES ($1) ON CONFLICT (email) DO UPDATE SET email = EXCLUDED.email RETURNING (xmax = 0) AS inserted"
+
+	if gotStr != wantStr {
+		t.Errorf("ReturningXmax string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.Insert([]string{"email"}, "a@b.c").Returning("id").ReturningXmax()
+
+	gotStr = q.String()
+	wantStr = "INSERT INTO users(email) VALUES ($1) RETURNING id,(xmax = 0) AS inserted"
+
+	if gotStr != wantStr {
+		t.Errorf("ReturningXmax after Returning string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestUpsertStruct(t *testing.T) {
+	type user struct {
+		Email   string `db:"email"`
+		Name    string
+		Visits  int    `db:"visits"`
+		Ignored string `db:"-"`
+		secret  string
+	}
+	u := user{Email: "a@b.c", Name: "n1", Visits: 3}
+
+	q := NewQuery("users")
+	q.UpsertStruct(&u, []string{"email"})
+
+	gotStr := q.String()
+	wantStr := "INSERT INTO users(email,name,visits) VALUES ($1,$2,$3) ON CONFLICT (email) DO UPDATE SET name=EXCLUDED.name,visits=EXCLUDED.visits"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{"a@b.c", "n1", 3}
+
+	if gotStr != wantStr {
+		t.Errorf("UpsertStruct string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("UpsertStruct arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("UpsertStruct arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q.SetDriver("mysql").UpsertStruct(u, []string{"email"})
+
+	gotStr = q.String()
+	wantStr = "INSERT INTO users(email,name,visits) VALUES (?,?,?) ON DUPLICATE KEY UPDATE name=VALUES(name),visits=VALUES(visits)"
+
+	if gotStr != wantStr {
+		t.Errorf("UpsertStruct mysql string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.SetPretty(true).SetDriver("pg").UpsertStruct(u, []string{"email"})
+
+	gotStr = q.String()
+	wantStr = "INSERT INTO users (email, name, visits) VALUES ($1, $2, $3) ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name, visits = EXCLUDED.visits"
+
+	if gotStr != wantStr {
+		t.Errorf("UpsertStruct pretty string: want %q, got %q", wantStr, gotStr)
+	}
+	q.SetPretty(false)
+
+	type key struct {
+		Email string `db:"email"`
+	}
+	q.SetDriver("pg").UpsertStruct(key{"a@b.c"}, []string{"email"})
+
+	gotStr = q.String()
+	wantStr = "INSERT INTO users(email) VALUES ($1) ON CONFLICT (email) DO NOTHING"
+
+	if gotStr != wantStr {
+		t.Errorf("UpsertStruct keys only string: want %q, got %q", wantStr, gotStr)
+	}
+
+	type post struct {
+		Tags []string
+		ID   int
+	}
+	q.UpsertStruct(post{Tags: []string{"a", "b"}, ID: 1}, []string{"id"})
+
+	gotStr = q.String()
+	wantStr = "INSERT INTO users(tags,id) VALUES ($1,$2) ON CONFLICT (id) DO UPDATE SET tags=EXCLUDED.tags"
+
+	if gotStr != wantStr {
+		t.Errorf("UpsertStruct slice field string: want %q, got %q", wantStr, gotStr)
+	}
+	if args := q.Args(); len(args) != 2 || args[1] != 1 {
+		t.Errorf("UpsertStruct slice field arguments: want [[a b] 1], got %v", args)
+	}
+
+	for _, driver := range []string{"pg", "sqlite"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("UpsertStruct without conflict columns on %s: want panic", driver)
+				}
+			}()
+			NewQuery("users").SetDriver(driver).UpsertStruct(u, nil)
+		}()
+	}
+}
+
+func TestConflictWhere(t *testing.T) {
+	q := NewQuery("users")
+	q.Insert([]string{"email", "name"}, "a@b.c", "n1").
+		ConflictWhere("deleted_at IS NULL").
+		OnConflictDoUpdate([]string{"email"}, "name = EXCLUDED.name").
+		Returning("id")
+
+	gotStr := q.String()
+	wantStr := "INSERT INTO users(email,name) VALUES ($1,$2) ON CONFLICT (email) WHERE deleted_at IS NULL DO UPDATE SET name = EXCLUDED.name RETURNING id"
+
+	if gotStr != wantStr {
+		t.Errorf("ConflictWhere string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.Insert([]string{"email"}, "a@b.c").ConflictWhere("active").OnConflictDoNothing("email")
+	if got, want := q.String(), "INSERT INTO users(email) VALUES ($1) ON CONFLICT (email) WHERE active DO NOTHING"; got != want {
+		t.Errorf("ConflictWhere do nothing string: want %q, got %q", want, got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("ConflictWhere without columns: want panic")
+		}
+	}()
+	q.Insert([]string{"email"}, "a@b.c").ConflictWhere("active").OnConflictDoNothing()
+}
+
+func TestInsertSelectOnConflict(t *testing.T) {
+	src := NewQuery("staging_users")
+	src.Select("email", "name").Where("batch = ?", 7)
+
+	q := NewQuery("users")
+	q.InsertSelect([]string{"email", "name"}, src.Statement()).
+		OnConflictDoUpdate([]string{"email"}, "name = EXCLUDED.name, synced = ?", true).
+		Returning("id")
+
+	gotStr := q.String()
+	wantStr := "INSERT INTO users(email,name) SELECT email,name FROM staging_users WHERE batch = $1 ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name, synced = $2 RETURNING id"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{7, true}
+
+	if gotStr != wantStr {
+		t.Errorf("InsertSelect string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("InsertSelect arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("InsertSelect arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	sqlite := NewQuery("users").SetDriver("sqlite")
+	sqlite.InsertSelect([]string{"email"}, NewQuery("staging_users").SetDriver("sqlite").Select("email")).OnConflictDoNothing("email")
+	if got, want := sqlite.String(), "INSERT INTO users(email) SELECT email FROM staging_users WHERE true ON CONFLICT (email) DO NOTHING"; got != want {
+		t.Errorf("InsertSelect sqlite string: want %q, got %q", want, got)
+	}
+
+	sqlite.InsertSelect([]string{"email"}, NewQuery("staging_users").SetDriver("sqlite").Select("email").Where("batch = ?", 7)).OnConflictDoNothing("email")
+	if got, want := sqlite.String(), "INSERT INTO users(email) SELECT email FROM staging_users WHERE batch = ? ON CONFLICT (email) DO NOTHING"; got != want {
+		t.Errorf("InsertSelect sqlite with where string: want %q, got %q", want, got)
+	}
+}
+
+func TestSqliteUpsert(t *testing.T) {
+	q := NewQuery("users").SetDriver("sqlite")
+	q.Insert([]string{"id", "name"}, 1, "n1").
+		OnConflictDoUpdate([]string{"id"}, "name = excluded.name, visits = visits + ?", 1).
+		Returning("id")
+
+	gotStr := q.String()
+	wantStr := "INSERT INTO users(id,name) VALUES (?,?) ON CONFLICT (id) DO UPDATE SET name = excluded.name, visits = visits + ? RETURNING id"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{1, "n1", 1}
+
+	if gotStr != wantStr {
+		t.Errorf("sqlite upsert string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("sqlite upsert arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("sqlite upsert arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q.Insert([]string{"email"}, "a@b.c").ConflictWhere("active").OnConflictDoNothing("email")
+	if got, want := q.String(), "INSERT INTO users(email) VALUES (?) ON CONFLICT (email) WHERE active DO NOTHING"; got != want {
+		t.Errorf("sqlite ConflictWhere string: want %q, got %q", want, got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("OnConflictDoUpdate mssql: want panic")
+		}
+	}()
+	q.SetDriver("mssql").Insert([]string{"id"}, 1).OnConflictDoUpdate([]string{"id"}, "id = id")
+}
@@ -0,0 +1,275 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"strings"
+)
+
+// Index describes a create index statement built by CreateIndex.
+type Index struct {
+	q            *Query
+	name         string
+	table        string
+	columns      []string
+	unique       bool
+	concurrently bool
+	method       string
+	where        string
+}
+
+// CreateIndex returns create index statement builder of name on table columns,
+// e.g. q.CreateIndex("users_email_idx", "users", "email").Unique().Statement().
+// columns are written as is so they can be expressions, e.g. "LOWER(email)".
+//
+// CreateIndex panics if name or table is not a valid name
+// or if columns is empty.
+func (q *Query) CreateIndex(name, table string, columns ...string) *Index {
+	if !isPlainIdent(name) {
+		panic("sqlbuilder.CreateIndex: invalid name: " + name)
+	}
+	if !isPlainName(table) {
+		panic("sqlbuilder.CreateIndex: invalid table: " + table)
+	}
+	if len(columns) == 0 {
+		panic("sqlbuilder.CreateIndex: columns cannot be empty")
+	}
+	return &Index{q: q, name: name, table: table, columns: columns}
+}
+
+// Unique makes index a unique index.
+func (ix *Index) Unique() *Index {
+	ix.unique = true
+	return ix
+}
+
+// Concurrently makes pg build index without locking writes to the table,
+// the statement can't run inside a transaction.
+//
+// Concurrently panics if driver is not pg.
+func (ix *Index) Concurrently() *Index {
+	if ix.q.driver != "pg" {
+		panic("sqlbuilder.Concurrently: unsupported by driver: " + ix.q.driver)
+	}
+	ix.concurrently = true
+	return ix
+}
+
+// Using sets index method, e.g. "gin" for pg or "HASH" for mysql.
+//
+// Using panics if method is not a valid identifier or driver is not pg or mysql.
+func (ix *Index) Using(method string) *Index {
+	if ix.q.driver != "pg" && ix.q.driver != "mysql" {
+		panic("sqlbuilder.Using: unsupported by driver: " + ix.q.driver)
+	}
+	if !isPlainIdent(method) {
+		panic("sqlbuilder.Using: invalid method: " + method)
+	}
+	ix.method = method
+	return ix
+}
+
+// Where makes index a partial index of the rows matching predicate,
+// predicate is written as is.
+//
+// Where panics if driver is mysql.
+func (ix *Index) Where(predicate string) *Index {
+	if ix.q.driver == "mysql" {
+		panic("sqlbuilder.Where: partial index unsupported by driver: " + ix.q.driver)
+	}
+	ix.where = predicate
+	return ix
+}
+
+// Statement writes create index statement to query and returns it,
+// "CREATE [UNIQUE] INDEX [CONCURRENTLY] name ON table [USING method] (columns) [WHERE predicate]"
+// with the method written after columns for mysql.
+func (ix *Index) Statement() *Statement {
+	q := ix.q
+	q.begin(KindCreate)
+	q.str.WriteString("CREATE ")
+	if ix.unique {
+		q.str.WriteString("UNIQUE ")
+	}
+	q.str.WriteString("INDEX ")
+	if ix.concurrently {
+		q.str.WriteString("CONCURRENTLY ")
+	}
+	q.str.WriteString(ix.name)
+	q.str.WriteString(" ON ")
+	q.str.WriteString(ix.table)
+	if ix.method != "" && q.driver == "pg" {
+		q.str.WriteString(" USING ")
+		q.str.WriteString(ix.method)
+	}
+	q.str.WriteString(" (")
+	q.addColumns(ix.columns...)
+	q.str.WriteByte(')')
+	if ix.method != "" && q.driver == "mysql" {
+		q.str.WriteString(" USING ")
+		q.str.WriteString(ix.method)
+	}
+	if ix.where != "" {
+		q.str.WriteString(" WHERE ")
+		q.str.WriteString(ix.where)
+	}
+	return q.Statement()
+}
+
+// Column describes an add column statement built by AddColumn.
+type Column struct {
+	q           *Query
+	table       string
+	name        string
+	typ         string
+	notNull     bool
+	ifNotExists bool
+	def         string
+	err         error
+}
+
+// AddColumn returns add column statement builder of column with type typ to table,
+// e.g. q.AddColumn("users", "age", "integer").NotNull().Default(0).Statement().
+// typ is written as is, e.g. "varchar(255)".
+//
+// AddColumn panics if table or column is not a valid name.
+func (q *Query) AddColumn(table, column, typ string) *Column {
+	if !isPlainName(table) {
+		panic("sqlbuilder.AddColumn: invalid table: " + table)
+	}
+	if !isPlainIdent(column) {
+		panic("sqlbuilder.AddColumn: invalid column: " + column)
+	}
+	return &Column{q: q, table: table, name: column, typ: typ}
+}
+
+// NotNull makes column not null.
+func (c *Column) NotNull() *Column {
+	c.notNull = true
+	return c
+}
+
+// IfNotExists makes pg skip adding column if it already exists.
+//
+// IfNotExists panics if driver is not pg.
+func (c *Column) IfNotExists() *Column {
+	if c.q.driver != "pg" {
+		panic("sqlbuilder.IfNotExists: unsupported by driver: " + c.q.driver)
+	}
+	c.ifNotExists = true
+	return c
+}
+
+// Default sets column default value, it's written as an sql literal
+// since ddl statements can't have arguments, values of type Expression
+// are written as is, e.g. Default(Expr("now()")).
+//
+// Default panics if v is an Expression with arguments.
+func (c *Column) Default(v interface{}) *Column {
+	if e, ok := v.(Expression); ok {
+		if len(e.args) != 0 {
+			panic("sqlbuilder.Default: expression cannot have arguments")
+		}
+		c.def = e.sql
+		return c
+	}
+	if s, ok := bindValue(v).(string); ok && c.q.driver == "mysql" {
+		// mysql strings treat backslash as an escape character.
+		v = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	c.def, c.err = literal(c.q.driver, bindValue(v))
+	return c
+}
+
+// Statement writes add column statement to query and returns it,
+// "ALTER TABLE table ADD COLUMN [IF NOT EXISTS] column typ [NOT NULL] [DEFAULT value]"
+// or without the COLUMN keyword for mssql.
+// The error of an invalid default value is set as query error.
+func (c *Column) Statement() *Statement {
+	q := c.q
+	q.begin(KindAlter)
+	if c.err != nil {
+		q.setErr(c.err)
+	}
+	q.str.WriteString("ALTER TABLE ")
+	q.str.WriteString(c.table)
+	if q.driver == "mssql" {
+		q.str.WriteString(" ADD ")
+	} else {
+		q.str.WriteString(" ADD COLUMN ")
+	}
+	if c.ifNotExists {
+		q.str.WriteString("IF NOT EXISTS ")
+	}
+	q.str.WriteString(c.name)
+	q.str.WriteByte(' ')
+	q.str.WriteString(c.typ)
+	if c.notNull {
+		q.str.WriteString(" NOT NULL")
+	}
+	if c.def != "" {
+		q.str.WriteString(" DEFAULT ")
+		q.str.WriteString(c.def)
+	}
+	return q.Statement()
+}
+
+// Confirm allows the next DropTable or DropIndex statement,
+// drop statements that aren't confirmed set query error.
+func (q *Query) Confirm() *Query {
+	q.pending.confirmDrop = true
+	return q
+}
+
+// DropTable returns "DROP TABLE [IF EXISTS] tables [CASCADE]" statement,
+// it must be confirmed by Confirm.
+//
+// DropTable panics if cascade is set and driver is not pg.
+func (q *Query) DropTable(ifExists, cascade bool) *Statement {
+	if cascade && q.driver != "pg" {
+		panic("sqlbuilder.DropTable: cascade unsupported by driver: " + q.driver)
+	}
+	q.beginDrop("sqlbuilder.DropTable")
+	q.str.WriteString("DROP TABLE ")
+	if ifExists {
+		q.str.WriteString("IF EXISTS ")
+	}
+	q.addTables()
+	if cascade {
+		q.str.WriteString(" CASCADE")
+	}
+	return q.Statement()
+}
+
+// DropIndex returns "DROP INDEX [IF EXISTS] name" statement,
+// with "ON table" of query's table for mysql and mssql,
+// it must be confirmed by Confirm.
+//
+// DropIndex panics if name is not a valid identifier
+// or if ifExists is set and driver is mysql.
+func (q *Query) DropIndex(name string, ifExists bool) *Statement {
+	if !isPlainIdent(name) {
+		panic("sqlbuilder.DropIndex: invalid name: " + name)
+	}
+	if ifExists && q.driver == "mysql" {
+		panic("sqlbuilder.DropIndex: if exists unsupported by driver: " + q.driver)
+	}
+	q.beginDrop("sqlbuilder.DropIndex")
+	q.str.WriteString("DROP INDEX ")
+	if ifExists {
+		q.str.WriteString("IF EXISTS ")
+	}
+	q.str.WriteString(name)
+	if q.driver == "mysql" || q.driver == "mssql" {
+		q.str.WriteString(" ON ")
+		q.addTables()
+	}
+	return q.Statement()
+}
+
+// beginDrop begins drop statement and sets query error if it's not confirmed.
+func (q *Query) beginDrop(caller string) {
+	q.begin(KindDrop)
+	if !q.mods.confirmDrop {
+		q.setErr(errors.New(caller + ": drop must be confirmed by Confirm"))
+	}
+}
@@ -0,0 +1,150 @@
+package sqlbuilder
+
+import "testing"
+
+func TestCreateIndex(t *testing.T) {
+	q := NewQuery()
+	s := q.CreateIndex("users_email_idx", "users", "LOWER(email)").
+		Unique().
+		Concurrently().
+		Where("deleted_at IS NULL").
+		Statement()
+
+	gotStr := s.String()
+	wantStr := "CREATE UNIQUE INDEX CONCURRENTLY users_email_idx ON users (LOWER(email)) WHERE deleted_at IS NULL"
+
+	if gotStr != wantStr {
+		t.Errorf("CreateIndex string: want %q, got %q", wantStr, gotStr)
+	}
+	if s.kind != KindCreate {
+		t.Errorf("CreateIndex kind: want %v, got %v", KindCreate, s.kind)
+	}
+
+	q.CreateIndex("docs_tags_idx", "docs", "tags").Using("gin").Statement()
+	if got, want := q.String(), "CREATE INDEX docs_tags_idx ON docs USING gin (tags)"; got != want {
+		t.Errorf("CreateIndex using string: want %q, got %q", want, got)
+	}
+
+	q = NewQuery().SetDriver("mysql")
+	q.CreateIndex("orders_user_idx", "shop.orders", "user_id", "created_at").Using("BTREE").Statement()
+	if got, want := q.String(), "CREATE INDEX orders_user_idx ON shop.orders (user_id,created_at) USING BTREE"; got != want {
+		t.Errorf("CreateIndex mysql string: want %q, got %q", want, got)
+	}
+
+	panics := []struct {
+		name string
+		fn   func()
+	}{
+		{"invalid name", func() { NewQuery().CreateIndex("idx; DROP", "users", "id") }},
+		{"no columns", func() { NewQuery().CreateIndex("idx", "users") }},
+		{"Concurrently mysql", func() { NewQuery().SetDriver("mysql").CreateIndex("idx", "users", "id").Concurrently() }},
+		{"Where mysql", func() { NewQuery().SetDriver("mysql").CreateIndex("idx", "users", "id").Where("active") }},
+		{"Using sqlite", func() { NewQuery().SetDriver("sqlite").CreateIndex("idx", "users", "id").Using("btree") }},
+	}
+	for _, tt := range panics {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("CreateIndex %s: want panic", tt.name)
+				}
+			}()
+			tt.fn()
+		}()
+	}
+}
+
+func TestAddColumn(t *testing.T) {
+	q := NewQuery()
+	s := q.AddColumn("users", "status", "text").IfNotExists().NotNull().Default("it's new").Statement()
+
+	gotStr := s.String()
+	wantStr := "ALTER TABLE users ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'it''s new'"
+
+	if gotStr != wantStr {
+		t.Errorf("AddColumn string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(s.Args()) != 0 {
+		t.Errorf("AddColumn arguments: want none, got %v", s.Args())
+	}
+
+	q.AddColumn("users", "created_at", "timestamptz").Default(Expr("now()")).Statement()
+	if got, want := q.String(), "ALTER TABLE users ADD COLUMN created_at timestamptz DEFAULT now()"; got != want {
+		t.Errorf("AddColumn expression default string: want %q, got %q", want, got)
+	}
+
+	q = NewQuery().SetDriver("mysql")
+	q.AddColumn("shop.orders", "priority", "TINYINT").NotNull().Default(orderPriority(1)).Statement()
+	if got, want := q.String(), "ALTER TABLE shop.orders ADD COLUMN priority TINYINT NOT NULL DEFAULT 1"; got != want {
+		t.Errorf("AddColumn mysql string: want %q, got %q", want, got)
+	}
+	q.AddColumn("files", "path", "VARCHAR(255)").Default(`C:\tmp`).Statement()
+	if got, want := q.String(), `ALTER TABLE files ADD COLUMN path VARCHAR(255) DEFAULT 'C:\\tmp'`; got != want {
+		t.Errorf("AddColumn mysql backslash string: want %q, got %q", want, got)
+	}
+
+	q = NewQuery().SetDriver("mssql")
+	q.AddColumn("users", "active", "bit").Default(true).Statement()
+	if got, want := q.String(), "ALTER TABLE users ADD active bit DEFAULT 1"; got != want {
+		t.Errorf("AddColumn mssql string: want %q, got %q", want, got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("IfNotExists mysql: want panic")
+		}
+	}()
+	NewQuery().SetDriver("mysql").AddColumn("users", "age", "INT").IfNotExists()
+}
+
+func TestDrop(t *testing.T) {
+	tests := []struct {
+		driver string
+		s      func(q *Query) *Statement
+		want   string
+	}{
+		{"pg", func(q *Query) *Statement { return q.DropTable(false, false) }, "DROP TABLE users"},
+		{"pg", func(q *Query) *Statement { return q.DropTable(true, true) }, "DROP TABLE IF EXISTS users CASCADE"},
+		{"mysql", func(q *Query) *Statement { return q.DropTable(true, false) }, "DROP TABLE IF EXISTS users"},
+		{"pg", func(q *Query) *Statement { return q.DropIndex("users_email_idx", false) }, "DROP INDEX users_email_idx"},
+		{"pg", func(q *Query) *Statement { return q.DropIndex("users_email_idx", true) }, "DROP INDEX IF EXISTS users_email_idx"},
+		{"mysql", func(q *Query) *Statement { return q.DropIndex("users_email_idx", false) }, "DROP INDEX users_email_idx ON users"},
+		{"mssql", func(q *Query) *Statement { return q.DropIndex("users_email_idx", true) }, "DROP INDEX IF EXISTS users_email_idx ON users"},
+	}
+	for _, tt := range tests {
+		s := tt.s(NewQuery("users").SetDriver(tt.driver).Confirm())
+		if got := s.String(); got != tt.want {
+			t.Errorf("Drop %s string: want %q, got %q", tt.driver, tt.want, got)
+		}
+		if err := s.Err(); err != nil {
+			t.Errorf("Drop %s error: want nil, got %v", tt.driver, err)
+		}
+	}
+
+	q := NewQuery("users")
+	if q.DropTable(true, false).Err() == nil {
+		t.Errorf("DropTable without Confirm: want error")
+	}
+	q.Confirm().DropIndex("users_email_idx", false)
+	if q.DropIndex("users_email_idx", false).Err() == nil {
+		t.Errorf("DropIndex after confirmed drop: want error")
+	}
+
+	panics := []struct {
+		name string
+		fn   func()
+	}{
+		{"DropTable cascade mysql", func() { NewQuery("users").SetDriver("mysql").DropTable(false, true) }},
+		{"DropIndex if exists mysql", func() { NewQuery("users").SetDriver("mysql").DropIndex("idx", true) }},
+		{"DropIndex invalid name", func() { NewQuery("users").DropIndex("idx; --", false) }},
+	}
+	for _, tt := range panics {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: want panic", tt.name)
+				}
+			}()
+			tt.fn()
+		}()
+	}
+}
@@ -0,0 +1,93 @@
+package sqlbuilder
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect describes sql features supported by a driver.
+type Dialect interface {
+	// Name returns driver name.
+	Name() string
+
+	// SupportsReturning reports whether RETURNING clause is supported.
+	SupportsReturning() bool
+
+	// SupportsOnConflict reports whether ON CONFLICT clause is supported.
+	SupportsOnConflict() bool
+}
+
+type dialect struct {
+	name       string
+	prefix     string // numbered placeholders prefix, empty for '?'.
+	returning  bool
+	onConflict bool
+	maxArgs    int
+
+	writePlaceholder func(q *Query)
+}
+
+func (d *dialect) Name() string {
+	return d.name
+}
+
+func (d *dialect) SupportsReturning() bool {
+	return d.returning
+}
+
+func (d *dialect) SupportsOnConflict() bool {
+	return d.onConflict
+}
+
+var dialects = map[string]*dialect{
+	"pg":     {name: "pg", prefix: "$", returning: true, onConflict: true, maxArgs: 65535},
+	"mysql":  {name: "mysql", maxArgs: 65535},
+	"sqlite": {name: "sqlite", returning: true, onConflict: true, maxArgs: 32766},
+	"mssql":  {name: "mssql", prefix: "@p", maxArgs: 2100},
+}
+
+func init() {
+	for _, d := range dialects {
+		d.writePlaceholder = placeholderWriter(d.prefix)
+	}
+}
+
+// placeholderWriter returns function writing placeholder of the last query argument,
+// numbered with prefix or '?' if prefix is empty.
+func placeholderWriter(prefix string) func(q *Query) {
+	if prefix == "" {
+		return func(q *Query) {
+			q.str.WriteByte('?')
+		}
+	}
+	return func(q *Query) {
+		var buf [20]byte
+		q.str.WriteString(prefix)
+		q.str.Write(strconv.AppendInt(buf[:0], int64(len(q.args)), 10))
+	}
+}
+
+// LookupDialect returns Dialect of driver and whether the driver is supported.
+func LookupDialect(driver string) (Dialect, bool) {
+	d, ok := dialects[driverName(driver)]
+	return d, ok
+}
+
+// driverName returns normalized name of driver.
+func driverName(driver string) string {
+	switch d := strings.ToLower(driver); d {
+	case "pg", "postgres", "postgresql":
+		return "pg"
+	case "sqlite", "sqlite3":
+		return "sqlite"
+	case "mssql", "sqlserver":
+		return "mssql"
+	default:
+		return d
+	}
+}
+
+// dialect returns query's driver dialect.
+func (q *Query) dialect() *dialect {
+	return dialects[q.driver]
+}
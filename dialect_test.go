@@ -0,0 +1,32 @@
+package sqlbuilder
+
+import "testing"
+
+func TestDialectReturning(t *testing.T) {
+	q := NewQuery("test").SetDriver("sqlite3")
+	q.Insert([]string{"name"}, "v1").Returning("id")
+
+	gotStr := q.String()
+	wantStr := "INSERT INTO test(name) VALUES (?) RETURNING id"
+
+	if gotStr != wantStr {
+		t.Errorf("Returning sqlite string: want %q, got %q", wantStr, gotStr)
+	}
+
+	for driver, want := range map[string]bool{"pg": true, "sqlite": true, "mysql": false} {
+		d, ok := LookupDialect(driver)
+		if !ok {
+			t.Fatalf("LookupDialect(%q): want ok", driver)
+		}
+		if got := d.SupportsReturning(); got != want {
+			t.Errorf("%s SupportsReturning: want %v, got %v", driver, want, got)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Returning on mysql: want panic")
+		}
+	}()
+	NewQuery("test").SetDriver("mysql").Insert([]string{"name"}, "v1").Returning("id")
+}
@@ -0,0 +1,79 @@
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+)
+
+// DB is the interface that wraps the ExecContext and QueryContext methods,
+// it's implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// SetLogger sets fn to be called with query string and arguments
+// before the statement is executed.
+func (q *Query) SetLogger(fn func(query string, args []interface{})) *Query {
+	q.logger = fn
+	return q
+}
+
+// SetDryRun sets whether statements are only logged without being executed,
+// execution helpers return zero results without touching the database.
+func (q *Query) SetDryRun(dryRun bool) *Query {
+	q.dryRun = dryRun
+	return q
+}
+
+// prepareExec returns query error and calls the logger,
+// it reports whether the statement should be executed.
+func (q *Query) prepareExec() (bool, error) {
+	if q.err != nil {
+		return false, q.err
+	}
+	if q.logger != nil {
+		q.logger(q.String(), q.args)
+	}
+	return !q.dryRun, nil
+}
+
+// ExecContext executes statement on db.
+func (s *Statement) ExecContext(ctx context.Context, db DB) (sql.Result, error) {
+	if ok, err := s.prepareExec(); !ok {
+		if err != nil {
+			return nil, err
+		}
+		return driver.RowsAffected(0), nil
+	}
+	return db.ExecContext(ctx, s.String(), s.args...)
+}
+
+// QueryContext executes statement on db and returns the resulting rows,
+// rows are nil in dry run mode.
+func (s *Statement) QueryContext(ctx context.Context, db DB) (*sql.Rows, error) {
+	if ok, err := s.prepareExec(); !ok {
+		return nil, err
+	}
+	return db.QueryContext(ctx, s.String(), s.args...)
+}
+
+// ExecExists executes select exists statement on db and returns the result,
+// it's false in dry run mode.
+func (s *Statement) ExecExists(ctx context.Context, db DB) (bool, error) {
+	rows, err := s.QueryContext(ctx, db)
+	if rows == nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	var exists bool
+	if rows.Next() {
+		err = rows.Scan(&exists)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	return exists, err
+}
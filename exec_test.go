@@ -0,0 +1,113 @@
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+)
+
+// fakeDB is a database/sql connector returning cols and rows for every query,
+// it records the last executed query and arguments.
+type fakeDB struct {
+	cols  []string
+	rows  [][]driver.Value
+	query string
+	args  []driver.Value
+}
+
+func (f *fakeDB) open() *sql.DB                                { return sql.OpenDB(f) }
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+func (f *fakeDB) Prepare(query string) (driver.Stmt, error)    { return &fakeStmt{f, query}, nil }
+func (f *fakeDB) Close() error                                 { return nil }
+func (f *fakeDB) Begin() (driver.Tx, error)                    { return nil, driver.ErrSkip }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.query, s.db.args = s.query, args
+	return driver.RowsAffected(len(s.db.rows)), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.query, s.db.args = s.query, args
+	return &fakeRows{db: s.db}, nil
+}
+
+type fakeRows struct {
+	db *fakeDB
+	i  int
+}
+
+func (r *fakeRows) Columns() []string { return r.db.cols }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.db.rows) {
+		return io.EOF
+	}
+	copy(dest, r.db.rows[r.i])
+	r.i++
+	return nil
+}
+
+func TestDryRun(t *testing.T) {
+	var logged string
+	var loggedArgs []interface{}
+	q := NewQuery("users").SetDryRun(true).SetLogger(func(query string, args []interface{}) {
+		logged = query
+		loggedArgs = args
+	})
+
+	res, err := q.Delete().Where("id = ?", 1).ExecContext(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ExecContext dry run: unexpected error: %v", err)
+	}
+	if n, _ := res.RowsAffected(); n != 0 {
+		t.Errorf("ExecContext dry run rows affected: want 0, got %d", n)
+	}
+	if want := "DELETE FROM users WHERE id = $1"; logged != want {
+		t.Errorf("ExecContext dry run logged string: want %q, got %q", want, logged)
+	}
+	if len(loggedArgs) != 1 || loggedArgs[0] != 1 {
+		t.Errorf("ExecContext dry run logged arguments: want [1], got %v", loggedArgs)
+	}
+
+	rows, err := q.Select().QueryContext(context.Background(), nil)
+	if err != nil || rows != nil {
+		t.Errorf("QueryContext dry run: want <nil>, <nil>, got %v, %v", rows, err)
+	}
+	if want := "SELECT * FROM users"; logged != want {
+		t.Errorf("QueryContext dry run logged string: want %q, got %q", want, logged)
+	}
+}
+
+func TestExecExists(t *testing.T) {
+	db := &fakeDB{cols: []string{"exists"}, rows: [][]driver.Value{{true}}}
+	q := NewQuery()
+	sub := NewQuery("users").Select("1").Where("email = ?", "a@b.c")
+
+	exists, err := q.SelectExists(sub).ExecExists(context.Background(), db.open())
+	if err != nil {
+		t.Fatalf("ExecExists: unexpected error: %v", err)
+	}
+	if !exists {
+		t.Errorf("ExecExists: want true")
+	}
+	if want := "SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)"; db.query != want {
+		t.Errorf("ExecExists query: want %q, got %q", want, db.query)
+	}
+
+	exists, err = q.SetDryRun(true).SelectExists(sub).ExecExists(context.Background(), nil)
+	if err != nil || exists {
+		t.Errorf("ExecExists dry run: want false, nil, got %v, %v", exists, err)
+	}
+}
@@ -0,0 +1,105 @@
+package sqlbuilder
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Plan describes a query plan parsed by ParseExplain.
+type Plan struct {
+	Nodes []PlanNode // nodes in output order, the root node first.
+}
+
+// PlanNode describes a node of query plan.
+type PlanNode struct {
+	Type        string // node type, e.g. "Seq Scan" or "Hash Join".
+	Relation    string // scanned relation, empty if node is not a scan.
+	Index       string // used index, empty if node is not an index scan.
+	Depth       int    // nesting level, 0 for the root node.
+	StartupCost float64
+	TotalCost   float64
+	Rows        int64
+	Width       int
+}
+
+// Find returns nodes of plan with type typ, e.g. p.Find("Seq Scan").
+func (p *Plan) Find(typ string) []PlanNode {
+	var nodes []PlanNode
+	for _, n := range p.Nodes {
+		if n.Type == typ {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// ParseExplain parses pg text EXPLAIN output into Plan,
+// output may include psql's "QUERY PLAN" header and rows footer.
+// Detail lines such as "Filter: ..." are skipped.
+func ParseExplain(output string) (*Plan, error) {
+	p := &Plan{}
+	for i, line := range strings.Split(output, "\n") {
+		at := strings.Index(line, "(cost=")
+		if at < 0 {
+			continue
+		}
+		n, err := parsePlanNode(line[:at], line[at+len("(cost="):])
+		if err != nil {
+			return nil, fmt.Errorf("sqlbuilder.ParseExplain: line %d: %w", i+1, err)
+		}
+		p.Nodes = append(p.Nodes, n)
+	}
+	if len(p.Nodes) == 0 {
+		return nil, errors.New("sqlbuilder.ParseExplain: no plan nodes")
+	}
+	return p, nil
+}
+
+// parsePlanNode parses node from head, e.g. "  ->  Seq Scan on users u  ",
+// and cost, e.g. "0.00..8.03 rows=1 width=4)".
+func parsePlanNode(head, cost string) (PlanNode, error) {
+	var n PlanNode
+	name := strings.TrimSpace(head)
+	if strings.HasPrefix(name, "->") {
+		// child nodes are indented by 6 spaces per level, "->" starts at 2.
+		n.Depth = (len(head)-len(strings.TrimLeft(head, " ")))/6 + 1
+		name = strings.TrimSpace(name[len("->"):])
+	}
+	if i := strings.Index(name, " on "); i >= 0 {
+		n.Relation = strings.Fields(name[i+len(" on "):])[0]
+		name = name[:i]
+	}
+	if i := strings.Index(name, " using "); i >= 0 {
+		n.Index = name[i+len(" using "):]
+		name = name[:i]
+	}
+	n.Type = name
+
+	if i := strings.IndexByte(cost, ')'); i >= 0 {
+		cost = cost[:i]
+	}
+	f := strings.Fields(cost)
+	if len(f) != 3 {
+		return n, fmt.Errorf("invalid cost %q", cost)
+	}
+	var err error
+	costs := strings.SplitN(f[0], "..", 2)
+	if len(costs) != 2 {
+		return n, fmt.Errorf("invalid cost %q", f[0])
+	}
+	if n.StartupCost, err = strconv.ParseFloat(costs[0], 64); err != nil {
+		return n, err
+	}
+	if n.TotalCost, err = strconv.ParseFloat(costs[1], 64); err != nil {
+		return n, err
+	}
+	if n.Rows, err = strconv.ParseInt(strings.TrimPrefix(f[1], "rows="), 10, 64); err != nil {
+		return n, err
+	}
+	if n.Width, err = strconv.Atoi(strings.TrimPrefix(f[2], "width=")); err != nil {
+		return n, err
+	}
+	return n, nil
+}
@@ -0,0 +1,42 @@
+package sqlbuilder
+
+import "testing"
+
+func TestParseExplain(t *testing.T) {
+	output := `                                  QUERY PLAN
+------------------------------------------------------------------------------
+ Nested Loop  (cost=0.29..16.34 rows=1 width=8)
+   ->  Index Scan using users_pkey on users u  (cost=0.29..8.30 rows=1 width=4)
+         Index Cond: (id = 1)
+   ->  Seq Scan on orders o  (cost=0.00..8.03 rows=12 width=4) (actual time=0.010..0.020 rows=3 loops=1)
+         Filter: (user_id = 1)
+(5 rows)`
+
+	p, err := ParseExplain(output)
+	if err != nil {
+		t.Fatalf("ParseExplain error: %v", err)
+	}
+	want := []PlanNode{
+		{Type: "Nested Loop", StartupCost: 0.29, TotalCost: 16.34, Rows: 1, Width: 8},
+		{Type: "Index Scan", Relation: "users", Index: "users_pkey", Depth: 1, StartupCost: 0.29, TotalCost: 8.30, Rows: 1, Width: 4},
+		{Type: "Seq Scan", Relation: "orders", Depth: 1, TotalCost: 8.03, Rows: 12, Width: 4},
+	}
+	if len(p.Nodes) != len(want) {
+		t.Fatalf("ParseExplain nodes length: want %d, got %d", len(want), len(p.Nodes))
+	}
+	for i, n := range p.Nodes {
+		if n != want[i] {
+			t.Errorf("ParseExplain nodes[%d]: want %+v, got %+v", i, want[i], n)
+		}
+	}
+	if scans := p.Find("Seq Scan"); len(scans) != 1 || scans[0].Relation != "orders" {
+		t.Errorf("Plan Find Seq Scan: want orders scan, got %+v", scans)
+	}
+
+	if _, err := ParseExplain("QUERY PLAN\n(0 rows)"); err == nil {
+		t.Errorf("ParseExplain without nodes: want error")
+	}
+	if _, err := ParseExplain("Seq Scan on t  (cost=x..1 rows=1 width=4)"); err == nil {
+		t.Errorf("ParseExplain invalid cost: want error")
+	}
+}
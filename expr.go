@@ -0,0 +1,36 @@
+package sqlbuilder
+
+// Expression describes a raw sql expression used in place of a value,
+// it's written inline instead of as a placeholder.
+type Expression struct {
+	sql  string
+	args []interface{}
+}
+
+// Expr returns Expression from sql and args,
+// e.g. Expr("now()") or Expr("v + ?", 1).
+func Expr(sql string, args ...interface{}) Expression {
+	return Expression{sql: sql, args: args}
+}
+
+// As returns "expr AS alias".
+func As(expr, alias string) string {
+	return expr + " AS " + alias
+}
+
+// Null is an Expression of sql NULL literal,
+// e.g. Update(map[string]interface{}{"deleted_at": Null}) writes "deleted_at=NULL".
+var Null = Expr("NULL")
+
+// Default is an Expression of sql DEFAULT keyword,
+// e.g. Update(map[string]interface{}{"status": Default}) writes "status=DEFAULT".
+var Default = Expr("DEFAULT")
+
+// Row returns Expression of a row constructor binding values,
+// e.g. Where("(a,b) = ?", Row(1, 2)) writes "(a,b) = ($1,$2)".
+func Row(values ...interface{}) Expression {
+	if len(values) == 0 {
+		panic("sqlbuilder.Row: values cannot be empty")
+	}
+	return Expr("("+placeholders(len(values))+")", values...)
+}
@@ -0,0 +1,148 @@
+package sqlbuilder
+
+import (
+	"sort"
+	"strings"
+)
+
+// Ratio returns "numerator / NULLIF(denominator, 0) AS alias" expression,
+// division by zero results in NULL instead of an error.
+// numerator is cast to a non integer type where needed to avoid integer division.
+// alias is omitted if it's empty.
+func (q *Query) Ratio(numerator, denominator, alias string) string {
+	q.setExprSpecific()
+	switch q.driver {
+	case "pg":
+		numerator += "::numeric"
+	case "sqlite":
+		numerator = "CAST(" + numerator + " AS REAL)"
+	case "mssql":
+		numerator = "CAST(" + numerator + " AS float)"
+	}
+	expr := numerator + " / NULLIF(" + denominator + ", 0)"
+	if alias != "" {
+		expr = As(expr, alias)
+	}
+	return expr
+}
+
+// Func returns "schema.name(args)" function call expression,
+// schema and name are quoted if they're not plain identifiers,
+// args are written as is. schema is omitted if it's empty.
+func (q *Query) Func(schema, name string, args ...string) string {
+	if !isPlainIdent(name) {
+		name = q.QuoteIdent(name)
+	}
+	if schema != "" {
+		if !isPlainIdent(schema) {
+			schema = q.QuoteIdent(schema)
+		}
+		name = schema + "." + name
+	}
+	return name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// SelectWithCast returns "col::typ AS alias" for pg and "CAST(col AS typ) AS alias"
+// for other drivers, to be used in a select list.
+// alias is omitted if it's empty.
+func (q *Query) SelectWithCast(col, typ, alias string) string {
+	q.setExprSpecific()
+	var expr string
+	if q.driver == "pg" {
+		expr = col + "::" + typ
+	} else {
+		expr = "CAST(" + col + " AS " + typ + ")"
+	}
+	if alias != "" {
+		expr = As(expr, alias)
+	}
+	return expr
+}
+
+// Greatest returns "GREATEST(exprs)" expression, supported by pg and mysql.
+func Greatest(exprs ...string) string {
+	return "GREATEST(" + strings.Join(exprs, ",") + ")"
+}
+
+// Least returns "LEAST(exprs)" expression, supported by pg and mysql.
+func Least(exprs ...string) string {
+	return "LEAST(" + strings.Join(exprs, ",") + ")"
+}
+
+// AggOption configures an aggregate function expression.
+type AggOption func(*aggOptions)
+
+type aggOptions struct {
+	distinct bool
+}
+
+// WithDistinct makes an aggregate function aggregate distinct values only,
+// e.g. "SUM(DISTINCT x)".
+func WithDistinct() AggOption {
+	return func(o *aggOptions) {
+		o.distinct = true
+	}
+}
+
+// Sum returns "SUM(expr)" expression.
+func Sum(expr string, opts ...AggOption) string {
+	return aggregate("SUM", expr, opts)
+}
+
+// Avg returns "AVG(expr)" expression.
+func Avg(expr string, opts ...AggOption) string {
+	return aggregate("AVG", expr, opts)
+}
+
+// CountCol returns "COUNT(expr)" expression, it counts non null values of expr.
+func CountCol(expr string, opts ...AggOption) string {
+	return aggregate("COUNT", expr, opts)
+}
+
+func aggregate(fn, expr string, opts []AggOption) string {
+	var o aggOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.distinct {
+		return fn + "(DISTINCT " + expr + ")"
+	}
+	return fn + "(" + expr + ")"
+}
+
+// SelectCounts returns select statement of a conditional count per filter,
+// filters maps column aliases to conditions, columns are ordered by alias.
+// Counts are "COUNT(*) FILTER (WHERE cond) AS alias" for pg and sqlite,
+// "SUM(cond) AS alias" for mysql and "SUM(CASE WHEN cond THEN 1 ELSE 0 END) AS alias" for mssql.
+// Conditions are written as is.
+//
+// SelectCounts panics if filters is empty or an alias is not a valid identifier.
+func (q *Query) SelectCounts(filters map[string]string) *Statement {
+	if len(filters) == 0 {
+		panic("sqlbuilder.SelectCounts: filters cannot be empty")
+	}
+	aliases := make([]string, 0, len(filters))
+	for alias := range filters {
+		if !isPlainIdent(alias) {
+			panic("sqlbuilder.SelectCounts: invalid alias: " + alias)
+		}
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	columns := make([]string, len(aliases))
+	for i, alias := range aliases {
+		cond := filters[alias]
+		switch q.driver {
+		case "mysql":
+			columns[i] = As("SUM("+cond+")", alias)
+		case "mssql":
+			columns[i] = As("SUM(CASE WHEN "+cond+" THEN 1 ELSE 0 END)", alias)
+		default:
+			columns[i] = As("COUNT(*) FILTER (WHERE "+cond+")", alias)
+		}
+	}
+	s := q.Select(columns...)
+	s.setSpecific()
+	return s
+}
@@ -0,0 +1,112 @@
+package sqlbuilder
+
+import "testing"
+
+func TestRatio(t *testing.T) {
+	q := NewQuery("campaigns")
+	q.Select("id", q.Ratio("clicks", "views", "ctr"))
+
+	gotStr := q.String()
+	wantStr := "SELECT id,clicks::numeric / NULLIF(views, 0) AS ctr FROM campaigns"
+
+	if gotStr != wantStr {
+		t.Errorf("Ratio string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.SetDriver("mysql")
+
+	if got, want := q.Ratio("clicks", "views", ""), "clicks / NULLIF(views, 0)"; got != want {
+		t.Errorf("Ratio mysql string: want %q, got %q", want, got)
+	}
+}
+
+func TestFunc(t *testing.T) {
+	q := NewQuery("users")
+	q.Select(q.Func("billing", "balance", "id", "'USD'")).Where(q.Func("my-schema", "is_active", "id")+" AND id = ?", 1)
+
+	gotStr := q.String()
+	wantStr := `SELECT billing.balance(id, 'USD') FROM users WHERE "my-schema".is_active(id) AND id = $1`
+
+	if gotStr != wantStr {
+		t.Errorf("Func string: want %q, got %q", wantStr, gotStr)
+	}
+
+	if got, want := q.Func("", "now"), "now()"; got != want {
+		t.Errorf("Func without schema string: want %q, got %q", want, got)
+	}
+}
+
+func TestSelectWithCast(t *testing.T) {
+	q := NewQuery("events")
+	q.Select("id", q.SelectWithCast("created_at", "date", "day"))
+
+	gotStr := q.String()
+	wantStr := "SELECT id,created_at::date AS day FROM events"
+
+	if gotStr != wantStr {
+		t.Errorf("SelectWithCast string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.SetDriver("mysql")
+
+	if got, want := q.SelectWithCast("price", "CHAR", "price_text"), "CAST(price AS CHAR) AS price_text"; got != want {
+		t.Errorf("SelectWithCast mysql string: want %q, got %q", want, got)
+	}
+}
+
+func TestGreatestLeast(t *testing.T) {
+	q := NewQuery("scores")
+	q.Select(As(Greatest("a", "b", "c"), "best")).Where(Least("a", "b", "c")+" > ?", 10)
+
+	gotStr := q.String()
+	wantStr := "SELECT GREATEST(a,b,c) AS best FROM scores WHERE LEAST(a,b,c) > $1"
+
+	if gotStr != wantStr {
+		t.Errorf("Greatest string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestAggregates(t *testing.T) {
+	q := NewQuery("orders")
+	q.Select(Sum("total"), Avg("total", WithDistinct()), CountCol("customer_id", WithDistinct())).GroupBy("shop_id")
+
+	gotStr := q.String()
+	wantStr := "SELECT SUM(total),AVG(DISTINCT total),COUNT(DISTINCT customer_id) FROM orders GROUP BY shop_id"
+
+	if gotStr != wantStr {
+		t.Errorf("Aggregates string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestSelectCounts(t *testing.T) {
+	filters := map[string]string{
+		"paid":     "status = 'paid'",
+		"refunded": "status = 'refunded'",
+		"large":    "total > 1000",
+	}
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{"pg", "SELECT COUNT(*) FILTER (WHERE total > 1000) AS large,COUNT(*) FILTER (WHERE status = 'paid') AS paid,COUNT(*) FILTER (WHERE status = 'refunded') AS refunded FROM orders WHERE created_at > $1"},
+		{"mysql", "SELECT SUM(total > 1000) AS large,SUM(status = 'paid') AS paid,SUM(status = 'refunded') AS refunded FROM orders WHERE created_at > ?"},
+		{"mssql", "SELECT SUM(CASE WHEN total > 1000 THEN 1 ELSE 0 END) AS large,SUM(CASE WHEN status = 'paid' THEN 1 ELSE 0 END) AS paid,SUM(CASE WHEN status = 'refunded' THEN 1 ELSE 0 END) AS refunded FROM orders WHERE created_at > @p1"},
+	}
+	for _, tt := range tests {
+		q := NewQuery("orders").SetDriver(tt.driver)
+		q.SelectCounts(filters).Where("created_at > ?", "2024-01-01")
+		if got := q.String(); got != tt.want {
+			t.Errorf("SelectCounts %s string: want %q, got %q", tt.driver, tt.want, got)
+		}
+		if args := q.Args(); len(args) != 1 || args[0] != "2024-01-01" {
+			t.Errorf("SelectCounts %s arguments: want [2024-01-01], got %v", tt.driver, args)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("SelectCounts invalid alias: want panic")
+		}
+	}()
+	NewQuery("orders").SelectCounts(map[string]string{"a b": "true"})
+}
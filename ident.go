@@ -0,0 +1,102 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QuoteIdent returns name quoted as an sql identifier for query's driver,
+// `name` for mysql, [name] for mssql and "name" for others.
+func (q *Query) QuoteIdent(name string) string {
+	q.setExprSpecific()
+	switch q.driver {
+	case "mysql":
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	case "mssql":
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	default:
+		return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	}
+}
+
+// QuoteName returns each dot separated part of name quoted as in QuoteIdent,
+// e.g. "db.users" is `db`.`users` for mysql.
+func (q *Query) QuoteName(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = q.QuoteIdent(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// CheckName returns an error if name is not a plain identifier
+// optionally qualified by up to two dot separated parts,
+// e.g. "users", "db.users" or "db.users.id".
+func CheckName(name string) error {
+	if strings.Count(name, ".") > 2 || !isPlainName(name) {
+		return fmt.Errorf("sqlbuilder.CheckName: invalid name: %q", name)
+	}
+	return nil
+}
+
+// qualify returns "table.column",
+// table and column are quoted if they're not plain identifiers,
+// table can be qualified by schema or database, e.g. "db.users",
+// each part of such table is quoted as in QuoteName.
+func (q *Query) qualify(table, column string) string {
+	if column != "*" && !isPlainIdent(column) {
+		column = q.QuoteIdent(column)
+	}
+	if !isPlainName(table) {
+		table = q.QuoteName(table)
+	}
+	return table + "." + column
+}
+
+// isPlainIdent reports whether s is an identifier that doesn't need quoting.
+func isPlainIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i != 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isPlainName reports whether s is a plain identifier
+// optionally qualified by dots, e.g. "schema.table".
+func isPlainName(s string) bool {
+	for _, part := range strings.Split(s, ".") {
+		if !isPlainIdent(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// Collate returns "expr COLLATE collation" with collation quoted for query's driver.
+func (q *Query) Collate(expr, collation string) string {
+	return expr + " COLLATE " + q.QuoteIdent(collation)
+}
+
+// Col returns column name qualified by table, e.g. "users.id",
+// table and name are quoted for query's driver if they're not plain identifiers.
+func (q *Query) Col(table, name string) string {
+	return q.qualify(table, name)
+}
+
+// Columns returns names qualified by table as in Col.
+func (q *Query) Columns(table string, names ...string) []string {
+	cols := make([]string, len(names))
+	for i, name := range names {
+		cols[i] = q.qualify(table, name)
+	}
+	return cols
+}
@@ -0,0 +1,75 @@
+package sqlbuilder
+
+import "testing"
+
+func TestCollate(t *testing.T) {
+	q := NewQuery("users")
+	q.Select("name").OrderBy(q.Collate("name", "de_DE"))
+
+	gotStr := q.String()
+	wantStr := `SELECT name FROM users ORDER BY name COLLATE "de_DE"`
+
+	if gotStr != wantStr {
+		t.Errorf("Collate string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q = NewQuery("users").SetDriver("mysql")
+	q.Select("name").Where(q.Collate("name", "utf8mb4_bin")+" = ?", "Bob")
+
+	gotStr = q.String()
+	wantStr = "SELECT name FROM users WHERE name COLLATE `utf8mb4_bin` = ?"
+
+	if gotStr != wantStr {
+		t.Errorf("Collate mysql string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestCol(t *testing.T) {
+	q := NewQuery("users")
+	q.Select(q.Columns("users", "id", "first name")...).Where(q.Col("users", "id")+" = ?", 1)
+
+	gotStr := q.String()
+	wantStr := `SELECT users.id,users."first name" FROM users WHERE users.id = $1`
+
+	if gotStr != wantStr {
+		t.Errorf("Col string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q = NewQuery("users").SetDriver("mysql")
+	if got, want := q.Col("orders", "user-id"), "orders.`user-id`"; got != want {
+		t.Errorf("Col mysql: want %q, got %q", want, got)
+	}
+	if got, want := q.Col("my table", "*"), "`my table`.*"; got != want {
+		t.Errorf("Col mysql star: want %q, got %q", want, got)
+	}
+}
+
+func TestCrossDatabaseJoin(t *testing.T) {
+	q := NewQuery("app.users AS u").SetDriver("mysql")
+	q.Select("u.id", q.Col("billing.invoices", "total")).
+		Join("billing.invoices", q.Col("billing.invoices", "user_id")+" = u.id AND "+q.Col("billing.invoices", "status")+" = ?", "paid")
+
+	gotStr := q.String()
+	wantStr := "SELECT u.id,billing.invoices.total FROM app.users AS u JOIN billing.invoices ON billing.invoices.user_id = u.id AND billing.invoices.status = ?"
+
+	if gotStr != wantStr {
+		t.Errorf("Cross database join string: want %q, got %q", wantStr, gotStr)
+	}
+	if got, want := q.Col("my-db.users", "id"), "`my-db`.`users`.id"; got != want {
+		t.Errorf("Col mysql quoted database: want %q, got %q", want, got)
+	}
+	if got, want := q.QuoteName("billing.invoices"), "`billing`.`invoices`"; got != want {
+		t.Errorf("QuoteName mysql: want %q, got %q", want, got)
+	}
+
+	for _, name := range []string{"users", "db.users", "db.users.id"} {
+		if err := CheckName(name); err != nil {
+			t.Errorf("CheckName %q: want no error, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "db..users", "a.b.c.d", "db.users;", ".users"} {
+		if CheckName(name) == nil {
+			t.Errorf("CheckName %q: want error", name)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package sqlbuilder
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interpolate returns query string with arguments inlined as sql literals.
+//
+// Interpolate is meant for logging and debugging only,
+// the result should never be executed.
+// Arguments implementing driver.Valuer are rendered using their Value.
+func (q *Query) Interpolate() (string, error) {
+	var err error
+	str := replacePlaceholders(q.String(), q.dialect().prefix, func(n int) string {
+		if n < 1 || n > len(q.args) {
+			if err == nil {
+				err = fmt.Errorf("sqlbuilder.Interpolate: missing argument %d", n)
+			}
+			return ""
+		}
+		s, e := literal(q.driver, q.args[n-1])
+		if e != nil && err == nil {
+			err = e
+		}
+		return s
+	})
+	if err != nil {
+		return "", err
+	}
+	return str, nil
+}
+
+// literal returns v as sql literal.
+func literal(driverName string, v interface{}) (string, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		dv, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		v = dv
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return quoteString(v), nil
+	case []byte:
+		switch driverName {
+		case "pg":
+			return `'\x` + hex.EncodeToString(v) + "'", nil
+		case "mssql":
+			return "0x" + hex.EncodeToString(v), nil
+		}
+		return "X'" + hex.EncodeToString(v) + "'", nil
+	case bool:
+		if driverName == "mssql" {
+			if v {
+				return "1", nil
+			}
+			return "0", nil
+		}
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case time.Time:
+		return quoteString(v.Format("2006-01-02 15:04:05.999999999Z07:00")), nil
+	default:
+		return quoteString(fmt.Sprint(v)), nil
+	}
+}
+
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,41 @@
+package sqlbuilder
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+)
+
+type testDecimal int64
+
+func (d testDecimal) Value() (driver.Value, error) {
+	return fmt.Sprintf("%d.%02d", d/100, d%100), nil
+}
+
+func TestInterpolate(t *testing.T) {
+	q := NewQuery("test")
+	q.Select().Where("price = ? AND name = ? AND deleted_at IS ? AND note = '?'", testDecimal(1250), "o'neil", nil)
+
+	got, err := q.Interpolate()
+	want := "SELECT * FROM test WHERE price = '12.50' AND name = 'o''neil' AND deleted_at IS NULL AND note = '?'"
+
+	if err != nil {
+		t.Fatalf("Interpolate: unexpected error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Interpolate string: want %q, got %q", want, got)
+	}
+
+	q = NewQuery("test").SetDriver("mysql")
+	q.Select().Where("id = ? AND active = ?", 5, true)
+
+	got, err = q.Interpolate()
+	want = "SELECT * FROM test WHERE id = 5 AND active = TRUE"
+
+	if err != nil {
+		t.Fatalf("Interpolate mysql: unexpected error: %v", err)
+	}
+	if got != want {
+		t.Errorf("Interpolate mysql string: want %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,100 @@
+package sqlbuilder
+
+// ForUpdate adds "FOR UPDATE" row locking to select statement.
+//
+// ForUpdate panics if driver is not pg or mysql.
+func (s *Statement) ForUpdate() *Statement {
+	return s.lock("sqlbuilder.ForUpdate", " FOR UPDATE", false)
+}
+
+// ForShare adds "FOR SHARE" row locking to select statement.
+//
+// ForShare panics if driver is not pg or mysql.
+func (s *Statement) ForShare() *Statement {
+	return s.lock("sqlbuilder.ForShare", " FOR SHARE", false)
+}
+
+// ForNoKeyUpdate adds pg "FOR NO KEY UPDATE" row locking to select statement,
+// unlike ForUpdate it doesn't block inserts referencing the locked rows.
+//
+// ForNoKeyUpdate panics if driver is not pg.
+func (s *Statement) ForNoKeyUpdate() *Statement {
+	return s.lock("sqlbuilder.ForNoKeyUpdate", " FOR NO KEY UPDATE", true)
+}
+
+// ForKeyShare adds pg "FOR KEY SHARE" row locking to select statement,
+// it only blocks deleting the locked rows and updating their keys.
+//
+// ForKeyShare panics if driver is not pg.
+func (s *Statement) ForKeyShare() *Statement {
+	return s.lock("sqlbuilder.ForKeyShare", " FOR KEY SHARE", true)
+}
+
+// SkipLocked adds "SKIP LOCKED" to row locking of query,
+// rows that can't be locked immediately are skipped.
+//
+// SkipLocked panics if query has no row locking.
+func (s *Statement) SkipLocked() *Statement {
+	return s.lockWait("sqlbuilder.SkipLocked", " SKIP LOCKED")
+}
+
+// NoWait adds "NOWAIT" to row locking of query,
+// the statement fails if a row can't be locked immediately.
+//
+// NoWait panics if query has no row locking.
+func (s *Statement) NoWait() *Statement {
+	return s.lockWait("sqlbuilder.NoWait", " NOWAIT")
+}
+
+// DequeueJob returns select statement of up to limit jobs with status
+// oldest first, locked and skipping jobs locked by other workers, e.g.
+// "SELECT * FROM jobs WHERE status = $1 ORDER BY created_at LIMIT $2 FOR UPDATE SKIP LOCKED".
+// The sql is the same for mysql 8.
+//
+// DequeueJob panics if driver is not pg or mysql.
+func (q *Query) DequeueJob(limit int, statusCol, statusVal string) *Statement {
+	if q.driver != "pg" && q.driver != "mysql" {
+		panic("sqlbuilder.DequeueJob: unsupported by driver: " + q.driver)
+	}
+	return q.Select().
+		Where(statusCol+" = ?", statusVal).
+		OrderBy("created_at").
+		Limit(limit).
+		ForUpdate().
+		SkipLocked()
+}
+
+// SelectForUpdateByIDs returns select statement of the rows with idColumn in ids
+// locked for update, e.g. "SELECT * FROM t WHERE id IN ($1,$2) FOR UPDATE",
+// ids are expanded like in WhereIn.
+//
+// SelectForUpdateByIDs panics if ids is not a slice or an array
+// or if driver is not pg or mysql.
+func (q *Query) SelectForUpdateByIDs(idColumn string, ids interface{}) *Statement {
+	if q.driver != "pg" && q.driver != "mysql" {
+		panic("sqlbuilder.SelectForUpdateByIDs: unsupported by driver: " + q.driver)
+	}
+	return q.Select().WhereIn(idColumn, ids).ForUpdate()
+}
+
+func (s *Statement) lock(caller, strength string, pgOnly bool) *Statement {
+	if s.driver != "pg" && (pgOnly || s.driver != "mysql") {
+		panic(caller + ": unsupported by driver: " + s.driver)
+	}
+	if s.kind != KindSelect {
+		panic(caller + ": must be used with select statement")
+	}
+	s.setSpecific()
+	// the rows cap must precede the locking clause.
+	s.addRowsCap()
+	s.writeClause(clauseLock, strength)
+	return s
+}
+
+func (s *Statement) lockWait(caller, option string) *Statement {
+	if s.clauses&clauseLock == 0 {
+		panic(caller + ": must follow row locking")
+	}
+	s.str.WriteString(option)
+	return s
+}
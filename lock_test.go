@@ -0,0 +1,112 @@
+package sqlbuilder
+
+import "testing"
+
+func TestLock(t *testing.T) {
+	tests := []struct {
+		s    *Statement
+		want string
+	}{
+		{NewQuery("jobs").Select().ForUpdate(), "SELECT * FROM jobs FOR UPDATE"},
+		{NewQuery("jobs").Select().ForShare().NoWait(), "SELECT * FROM jobs FOR SHARE NOWAIT"},
+		{NewQuery("jobs").Select().ForNoKeyUpdate().SkipLocked(), "SELECT * FROM jobs FOR NO KEY UPDATE SKIP LOCKED"},
+		{NewQuery("jobs").Select().ForKeyShare(), "SELECT * FROM jobs FOR KEY SHARE"},
+		{NewQuery("jobs").SetDriver("mysql").Select().Limit(1).ForUpdate().SkipLocked(), "SELECT * FROM jobs LIMIT ? FOR UPDATE SKIP LOCKED"},
+	}
+	for _, tt := range tests {
+		if got := tt.s.String(); got != tt.want {
+			t.Errorf("Lock string: want %q, got %q", tt.want, got)
+		}
+	}
+}
+
+func TestLockPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"ForNoKeyUpdate mysql", func() { NewQuery("jobs").SetDriver("mysql").Select().ForNoKeyUpdate() }},
+		{"ForKeyShare mysql", func() { NewQuery("jobs").SetDriver("mysql").Select().ForKeyShare() }},
+		{"ForUpdate sqlite", func() { NewQuery("jobs").SetDriver("sqlite").Select().ForUpdate() }},
+		{"ForUpdate delete", func() { NewQuery("jobs").Delete().ForUpdate() }},
+		{"SkipLocked without lock", func() { NewQuery("jobs").Select().SkipLocked() }},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: want panic", tt.name)
+				}
+			}()
+			tt.fn()
+		}()
+	}
+}
+
+func TestDequeueJob(t *testing.T) {
+	q := NewQuery("jobs")
+	q.DequeueJob(10, "status", "queued")
+
+	gotStr := q.String()
+	wantStr := "SELECT * FROM jobs WHERE status = $1 ORDER BY created_at LIMIT $2 FOR UPDATE SKIP LOCKED"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{"queued", 10}
+
+	if gotStr != wantStr {
+		t.Errorf("DequeueJob string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("DequeueJob arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("DequeueJob arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q.SetDriver("mysql").DequeueJob(1, "state", "new")
+	if got, want := q.String(), "SELECT * FROM jobs WHERE state = ? ORDER BY created_at LIMIT ? FOR UPDATE SKIP LOCKED"; got != want {
+		t.Errorf("DequeueJob mysql string: want %q, got %q", want, got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("DequeueJob sqlite: want panic")
+		}
+	}()
+	q.SetDriver("sqlite").DequeueJob(1, "status", "queued")
+}
+
+func TestSelectForUpdateByIDs(t *testing.T) {
+	q := NewQuery("accounts")
+	q.SelectForUpdateByIDs("id", []int64{4, 8, 15})
+
+	gotStr := q.String()
+	wantStr := "SELECT * FROM accounts WHERE id IN ($1,$2,$3) FOR UPDATE"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{int64(4), int64(8), int64(15)}
+
+	if gotStr != wantStr {
+		t.Errorf("SelectForUpdateByIDs string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("SelectForUpdateByIDs arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("SelectForUpdateByIDs arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q.SetDriver("mysql").SelectForUpdateByIDs("uuid", []string{"a", "b"})
+	if got, want := q.String(), "SELECT * FROM accounts WHERE uuid IN (?,?) FOR UPDATE"; got != want {
+		t.Errorf("SelectForUpdateByIDs mysql string: want %q, got %q", want, got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("SelectForUpdateByIDs sqlite: want panic")
+		}
+	}()
+	q.SetDriver("sqlite").SelectForUpdateByIDs("id", []int{1})
+}
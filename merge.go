@@ -0,0 +1,63 @@
+package sqlbuilder
+
+// Merge returns sql merge statement into query's table using source,
+// source is written as is so it can be a table or a parenthesized sub query with an alias.
+// It should be followed by On and When clauses, e.g.
+//
+//	q.Merge("new_users s").On("t.id = s.id").
+//		WhenMatchedUpdate("name = s.name").
+//		WhenNotMatchedInsert([]string{"id", "name"}, Expr("s.id"), Expr("s.name"))
+//
+// mssql requires the statement to be terminated, see SetTerminate.
+//
+// Merge panics if driver is not pg or mssql.
+func (q *Query) Merge(source string) *Statement {
+	if q.driver != "pg" && q.driver != "mssql" {
+		panic("sqlbuilder.Merge: unsupported by driver: " + q.driver)
+	}
+	q.begin(KindMerge)
+	q.setSpecific()
+	q.str.WriteString("MERGE INTO ")
+	q.addTables()
+	q.str.WriteString(" USING ")
+	q.str.WriteString(source)
+	return q.Statement()
+}
+
+// On adds merge join condition to query.
+func (s *Statement) On(cond string, args ...interface{}) *Statement {
+	s.str.WriteString(" ON ")
+	s.Raw(cond, args...)
+	return s
+}
+
+// WhenMatchedUpdate adds "WHEN MATCHED THEN UPDATE SET data" to merge statement,
+// data type can be string or map[string]interface{} like in Update.
+func (s *Statement) WhenMatchedUpdate(data interface{}, args ...interface{}) *Statement {
+	s.str.WriteString(" WHEN MATCHED THEN UPDATE SET ")
+	s.addSet("sqlbuilder.WhenMatchedUpdate", data, args...)
+	return s
+}
+
+// WhenMatchedDelete adds "WHEN MATCHED THEN DELETE" to merge statement.
+func (s *Statement) WhenMatchedDelete() *Statement {
+	s.str.WriteString(" WHEN MATCHED THEN DELETE")
+	return s
+}
+
+// WhenNotMatchedInsert adds "WHEN NOT MATCHED THEN INSERT (columns) VALUES (values)"
+// to merge statement, values of type Expression are written inline,
+// e.g. Expr("s.id") to insert a source column.
+func (s *Statement) WhenNotMatchedInsert(columns []string, values ...interface{}) *Statement {
+	s.str.WriteString(" WHEN NOT MATCHED THEN INSERT (")
+	s.addColumns(columns...)
+	s.str.WriteString(") VALUES (")
+	for i, v := range values {
+		if i != 0 {
+			s.addComma()
+		}
+		s.addArg(v)
+	}
+	s.str.WriteByte(')')
+	return s
+}
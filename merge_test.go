@@ -0,0 +1,47 @@
+package sqlbuilder
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	q := NewQuery("users t")
+	q.Merge("new_users s").On("t.id = s.id").
+		WhenMatchedUpdate("name = s.name, updated_at = ?", "2020-01-01").
+		WhenNotMatchedInsert([]string{"id", "name", "source"}, Expr("s.id"), Expr("s.name"), "import")
+
+	gotStr := q.String()
+	wantStr := "MERGE INTO users t USING new_users s ON t.id = s.id WHEN MATCHED THEN UPDATE SET name = s.name, updated_at = $1 WHEN NOT MATCHED THEN INSERT (id,name,source) VALUES (s.id,s.name,$2)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{"2020-01-01", "import"}
+
+	if gotStr != wantStr {
+		t.Errorf("Merge string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Merge arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Merge arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+	if q.kind != KindMerge {
+		t.Errorf("Merge kind: want %v, got %v", KindMerge, q.kind)
+	}
+
+	q = NewQuery("users t").SetDriver("mssql").SetTerminate(true)
+	q.Merge("new_users s").On("t.id = s.id").WhenMatchedDelete()
+
+	gotStr = q.String()
+	wantStr = "MERGE INTO users t USING new_users s ON t.id = s.id WHEN MATCHED THEN DELETE;"
+
+	if gotStr != wantStr {
+		t.Errorf("Merge mssql string: want %q, got %q", wantStr, gotStr)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Merge with mysql driver: want panic")
+		}
+	}()
+	NewQuery("users").SetDriver("mysql").Merge("new_users")
+}
@@ -0,0 +1,28 @@
+package sqlbuilder
+
+import "sync"
+
+var queryPool = sync.Pool{
+	New: func() interface{} {
+		return NewQuery()
+	},
+}
+
+// AcquireQuery returns a Query with tables from a pool,
+// it's like NewQuery but reuses queries released by ReleaseQuery
+// to reduce allocations in high-throughput servers.
+func AcquireQuery(tables ...string) *Query {
+	q := queryPool.Get().(*Query)
+	q.tables = tables
+	return q
+}
+
+// ReleaseQuery resets q to its defaults and returns it to the pool.
+//
+// q, its statements and the slice returned by Args must not be used
+// after ReleaseQuery is called, they'll be reused by another caller.
+// Use Compile to keep the query string and arguments.
+func ReleaseQuery(q *Query) {
+	q.init(nil)
+	queryPool.Put(q)
+}
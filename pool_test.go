@@ -0,0 +1,41 @@
+package sqlbuilder
+
+import "testing"
+
+func TestReleaseQuery(t *testing.T) {
+	q := AcquireQuery("users").SetDriver("mysql").SetPretty(true)
+	q.Distinct().Select("id").Where("id = ?", 1)
+	ReleaseQuery(q)
+
+	q = AcquireQuery("orders")
+	defer ReleaseQuery(q)
+	q.Select("id").Where("id = ?", 2)
+
+	gotStr := q.String()
+	wantStr := "SELECT id FROM orders WHERE id = $1"
+
+	if gotStr != wantStr {
+		t.Errorf("AcquireQuery string: want %q, got %q", wantStr, gotStr)
+	}
+	if got := q.Args(); len(got) != 1 || got[0] != 2 {
+		t.Errorf("AcquireQuery arguments: want [2], got %v", got)
+	}
+}
+
+func BenchmarkAcquireQuery(b *testing.B) {
+	b.Run("NewQuery", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			q := NewQuery("users")
+			q.Select("id", "name").Where("id = ?", 1)
+		}
+	})
+	b.Run("AcquireQuery", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			q := AcquireQuery("users")
+			q.Select("id", "name").Where("id = ?", 1)
+			ReleaseQuery(q)
+		}
+	})
+}
@@ -1,9 +1,14 @@
 package sqlbuilder
 
 import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Query describes an sql query.
@@ -12,27 +17,250 @@ type Query struct {
 	args   []interface{}
 	tables []string
 	driver string
+	kind   Kind
+	pretty bool
+	err    error
+
+	terminate bool // query string is terminated by a semicolon.
+
+	specific     bool  // statement has sql specific to its driver, see Dialect.
+	exprErr      error // error of an expression helper, kept for the next statement.
+	exprSpecific bool  // an expression helper wrote sql specific to driver, kept like exprErr.
+	exprAt       int   // query string length when exprErr or exprSpecific was set.
+
+	maxArgs     int
+	maxRows     int
+	returningAt int
+	limitAt     int    // start of mssql limit clause.
+	clauses     clause // clauses written to the statement.
+	stmtAt      int    // start of the statement after its with clause.
+	whereStart  int    // start of the where clause.
+	whereArgs   int    // number of arguments before the where clause.
+	whereAt     int    // start of the where conditions.
+	orGroup     bool   // where conditions end with a group written by Or.
+	selectCols  int    // number of select list columns, 0 if unknown.
+	selectList  []string
+
+	placeholder func(q *Query) // writes placeholder of the last argument.
+
+	logger func(query string, args []interface{})
+	dryRun bool
+
+	argHint  int
+	byteHint int
+
+	pending modifiers // modifiers of the next statement.
+	mods    modifiers // modifiers of the statement being built.
+	with    *Query    // pending with clause.
+
+	conflictWhere string // pending conflict target predicate.
+	selectWhere   bool   // sqlite insert select needs "WHERE true" before ON CONFLICT.
+
+	orderColumns   map[string]bool
+	defaultOrderBy []string
+}
+
+// modifiers describes one-shot modifiers set before a statement is built,
+// every statement takes the pending modifiers when it begins,
+// those it doesn't use are dropped.
+type modifiers struct {
+	only           bool
+	distinct       bool
+	distinctOn     []string
+	tableSample    string
+	hint           string
+	totalWindow    bool
+	confirmCascade bool // truncate may cascade.
+	confirmDrop    bool // drop statement is confirmed.
 }
 
+// Kind describes an sql statement kind.
+type Kind int
+
+// Statement kinds.
+const (
+	KindRaw Kind = iota
+	KindSelect
+	KindInsert
+	KindUpdate
+	KindDelete
+	KindTruncate
+	KindMerge
+	KindCreate
+	KindAlter
+	KindDrop
+)
+
+var kindNames = [...]string{"RAW", "SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "MERGE", "CREATE", "ALTER", "DROP"}
+
+// String returns kind name.
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return kindNames[k]
+}
+
+// clause is a set of statement clauses.
+type clause uint16
+
+const (
+	clauseWhere clause = 1 << iota
+	clauseOrderBy
+	clauseLimit
+	clauseJoin
+	clauseUnion
+	clauseLock
+	clauseGroupBy
+	clauseHaving
+	clauseOffset
+	clauseReturning
+
+	// clausesAfterWhere are the clauses written after where conditions.
+	clausesAfterWhere = clauseGroupBy | clauseHaving | clauseOrderBy | clauseLimit |
+		clauseOffset | clauseUnion | clauseLock | clauseReturning
+)
+
 // NewQuery returns new Query with table.
 func NewQuery(tables ...string) *Query {
-	return &Query{
-		str:    &strings.Builder{},
-		tables: tables,
-		driver: "pg",
+	q := &Query{str: &strings.Builder{}}
+	q.init(tables)
+	return q
+}
+
+// init sets query fields to their defaults keeping only the string builder.
+func (q *Query) init(tables []string) {
+	str := q.str
+	str.Reset()
+	*q = Query{
+		str:         str,
+		tables:      tables,
+		driver:      "pg",
+		maxArgs:     dialects["pg"].maxArgs,
+		returningAt: -1,
+		placeholder: dialects["pg"].writePlaceholder,
 	}
 }
 
-// Reset resets query string and arguments.
+// NewQueryCap returns new Query with table, every statement built
+// preallocates capacity for argHint arguments and byteHint bytes of query string.
+func NewQueryCap(argHint, byteHint int, tables ...string) *Query {
+	q := NewQuery(tables...)
+	q.argHint = argHint
+	q.byteHint = byteHint
+	return q
+}
+
+// Reset resets query string, arguments, error and pending modifiers.
 func (q *Query) Reset() *Query {
+	q.exprErr = nil
+	q.exprSpecific = false
+	q.with = nil
+	q.pending = modifiers{}
+	q.begin(KindRaw)
+	return q
+}
+
+// begin resets query string and arguments to start a statement of kind k,
+// pending modifiers are taken by the statement.
+func (q *Query) begin(k Kind) {
+	q.mods, q.pending = q.pending, modifiers{}
+	q.err, q.specific = nil, false
+	if q.str.Len() == q.exprAt {
+		q.err, q.specific = q.exprErr, q.exprSpecific
+	}
+	q.exprErr, q.exprSpecific = nil, false
 	q.str.Reset()
+	if q.byteHint > 0 {
+		q.str.Grow(q.byteHint)
+	}
 	q.args = nil
-	return q
+	q.kind = k
+	q.clauses = 0
+	q.selectCols = 0
+	q.selectList = nil
+	q.returningAt = -1
+	q.conflictWhere = ""
+	q.selectWhere = false
+	if q.with != nil {
+		q.embed(q.with)
+		q.str.WriteByte(' ')
+		q.with = nil
+	}
+	q.stmtAt = q.str.Len()
+}
+
+// Err returns the first error occurred while building the statement.
+func (q *Query) Err() error {
+	return q.err
+}
+
+// setErr sets query error if it has none.
+func (q *Query) setErr(err error) {
+	if q.err == nil {
+		q.err = err
+	}
+}
+
+// setExprErr sets query error of an expression helper,
+// the error is also kept for the next statement since helpers
+// are usually called before the statement they're passed to begins,
+// it's dropped if the current statement is written to before then.
+func (q *Query) setExprErr(err error) {
+	q.setErr(err)
+	q.markExpr()
+	if q.exprErr == nil {
+		q.exprErr = err
+	}
 }
 
-// String returns query string.
+// setSpecific marks statement as having sql specific to query driver.
+func (q *Query) setSpecific() {
+	q.specific = true
+}
+
+// setExprSpecific is like setSpecific for expression helpers,
+// the mark is kept for the next statement like the error of setExprErr.
+func (q *Query) setExprSpecific() {
+	q.specific = true
+	q.markExpr()
+	q.exprSpecific = true
+}
+
+// markExpr drops the error and specific mark kept for the next statement
+// if the current statement was written to since they were set.
+func (q *Query) markExpr() {
+	if q.str.Len() != q.exprAt {
+		q.exprErr = nil
+		q.exprSpecific = false
+		q.exprAt = q.str.Len()
+	}
+}
+
+// String returns query string,
+// terminated by a semicolon if SetTerminate is set.
 func (q *Query) String() string {
-	return q.str.String()
+	str := q.str.String()
+	if c := q.rowsCap(); c != "" {
+		str += c
+	}
+	if q.terminated() {
+		return str + ";"
+	}
+	return str
+}
+
+// terminated reports whether query string should be terminated by a semicolon.
+func (q *Query) terminated() bool {
+	n := q.str.Len()
+	return q.terminate && n > 0 && q.str.String()[n-1] != ';'
+}
+
+// SetTerminate sets whether query string is terminated by a semicolon,
+// e.g. for migration runners. It's off by default.
+func (q *Query) SetTerminate(terminate bool) *Query {
+	q.terminate = terminate
+	return q
 }
 
 // Args returns query arguments.
@@ -40,8 +268,21 @@ func (q *Query) Args() []interface{} {
 	return q.args
 }
 
-// Table returns first table name.
+// Arg returns the i-th query argument, i is 1-based to match "$i" placeholders.
+//
+// Arg panics if i is out of range.
+func (q *Query) Arg(i int) interface{} {
+	if i < 1 || i > len(q.args) {
+		panic("sqlbuilder.Arg: index out of range: " + strconv.Itoa(i))
+	}
+	return q.args[i-1]
+}
+
+// Table returns first table name, it's empty if query has no tables.
 func (q *Query) Table() string {
+	if len(q.tables) == 0 {
+		return ""
+	}
 	return q.tables[0]
 }
 
@@ -53,10 +294,26 @@ func (q *Query) Tables() []string {
 // SetTable sets first table in tables field and calls Reset.
 func (q *Query) SetTable(table string) *Query {
 	q.Reset()
+	if len(q.tables) == 0 {
+		q.tables = []string{table}
+		return q
+	}
 	q.tables[0] = table
 	return q
 }
 
+// OnTable sets first table in tables field without calling Reset,
+// pending modifiers and query settings are kept.
+// It only affects statements built after it's called.
+func (q *Query) OnTable(table string) *Query {
+	if len(q.tables) == 0 {
+		q.tables = []string{table}
+		return q
+	}
+	q.tables = append([]string{table}, q.tables[1:]...)
+	return q
+}
+
 // SetTables sets tables field and calls Reset.
 func (q *Query) SetTables(tables ...string) *Query {
 	q.Reset()
@@ -64,17 +321,204 @@ func (q *Query) SetTables(tables ...string) *Query {
 	return q
 }
 
-// SetDriver sets driver field to the given value.
+// Driver returns query's driver.
+func (q *Query) Driver() string {
+	return q.driver
+}
+
+// SetDriver sets driver field to the given value
+// and resets max arguments to the driver's default.
 // SetDriver panics if driver is not supported.
 func (q *Query) SetDriver(driver string) *Query {
-	switch d := strings.ToLower(driver); d {
-	case "pg", "postgres", "postgresql":
-		q.driver = "pg"
-	case "mysql":
-		q.driver = d
-	default:
+	name := driverName(driver)
+	if _, ok := dialects[name]; !ok {
 		panic("sqlbuilder.SetDriver: unsupported driver: " + driver)
 	}
+	q.driver = name
+	q.maxArgs = dialects[name].maxArgs
+	q.placeholder = dialects[name].writePlaceholder
+	return q
+}
+
+// SetMaxArgs sets the max number of arguments of a statement,
+// exceeding it sets query error instead of failing when executed.
+// n <= 0 disables the check.
+func (q *Query) SetMaxArgs(n int) *Query {
+	q.maxArgs = n
+	return q
+}
+
+// SetMaxRows caps select statements at n rows, a select without limit
+// is written with "LIMIT n" and a larger limit is clamped to n,
+// the cap of mssql is "OFFSET 0 ROWS FETCH NEXT n ROWS ONLY".
+// Sub queries are capped too except for operands of Union.
+// LimitAll and LimitSub are not capped.
+// n <= 0 disables the cap.
+func (q *Query) SetMaxRows(n int) *Query {
+	q.maxRows = n
+	return q
+}
+
+// rowsCap returns the limit clause capping a select statement without limit
+// at max rows, or empty string if it's not needed.
+func (q *Query) rowsCap() string {
+	if q.maxRows <= 0 || q.kind != KindSelect || q.clauses&clauseLimit != 0 {
+		return ""
+	}
+	n := strconv.Itoa(q.maxRows)
+	if q.driver != "mssql" {
+		return " LIMIT " + n
+	}
+	if q.clauses&clauseOffset != 0 {
+		return " FETCH NEXT " + n + " ROWS ONLY"
+	}
+	var order string
+	if q.clauses&clauseOrderBy == 0 {
+		order = " ORDER BY (SELECT NULL)"
+	}
+	return order + " OFFSET 0 ROWS FETCH NEXT " + n + " ROWS ONLY"
+}
+
+// SetOrderByColumns sets the columns allowed by OrderByUserInput.
+func (q *Query) SetOrderByColumns(columns ...string) *Query {
+	q.orderColumns = make(map[string]bool, len(columns))
+	for _, c := range columns {
+		q.orderColumns[c] = true
+	}
+	return q
+}
+
+// SetDefaultOrderBy sets columns select statements are ordered by
+// when they're paginated with Limit or Offset without an order by,
+// so pages are deterministic.
+func (q *Query) SetDefaultOrderBy(columns ...string) *Query {
+	q.defaultOrderBy = columns
+	return q
+}
+
+// SetPretty sets whether query string is written with spaces
+// after commas and around keywords and operators,
+// e.g. "INSERT INTO t (a, b) VALUES ($1, $2)" instead of
+// "INSERT INTO t(a,b) VALUES ($1,$2)".
+func (q *Query) SetPretty(pretty bool) *Query {
+	q.pretty = pretty
+	return q
+}
+
+// addComma writes a comma separator to query string.
+func (q *Query) addComma() {
+	q.str.WriteString(q.comma())
+}
+
+// comma returns the separator written by addComma.
+func (q *Query) comma() string {
+	if q.pretty {
+		return ", "
+	}
+	return ","
+}
+
+// Only makes the next select, update, delete, truncate or merge statement
+// emit "ONLY table" to exclude inheriting tables.
+//
+// Only panics if driver is not pg,
+// other statements panic if they are built after Only.
+func (q *Query) Only() *Query {
+	if q.driver != "pg" {
+		panic("sqlbuilder.Only: unsupported by driver: " + q.driver)
+	}
+	q.pending.only = true
+	return q
+}
+
+// With makes the next statement start with "WITH name AS (sub)",
+// calling With again adds more common table expressions.
+// sub can be a select or, for pg only, an insert, update or delete
+// statement usually with returning.
+//
+// With panics if sub is an insert, update or delete statement and driver is not pg.
+func (q *Query) With(name string, sub *Statement) *Query {
+	switch sub.kind {
+	case KindInsert, KindUpdate, KindDelete:
+		if q.driver != "pg" {
+			panic("sqlbuilder.With: " + sub.kind.String() + " statement unsupported by driver: " + q.driver)
+		}
+	}
+	if q.with == nil {
+		q.with = NewQuery().SetDriver(q.driver).SetPretty(q.pretty)
+		q.with.str.WriteString("WITH ")
+	} else {
+		q.with.addComma()
+	}
+	q.with.str.WriteString(name)
+	q.with.str.WriteString(" AS (")
+	q.with.embed(sub.Query)
+	q.with.str.WriteByte(')')
+	return q
+}
+
+// TableSample makes the next select statement sample its first table with
+// "TABLESAMPLE method (percentage)", method is SYSTEM or BERNOULLI.
+//
+// TableSample panics if driver is not pg, method is invalid
+// or percentage is not between 0 and 100.
+func (q *Query) TableSample(method string, percentage float64) *Query {
+	if q.driver != "pg" {
+		panic("sqlbuilder.TableSample: unsupported by driver: " + q.driver)
+	}
+	method = strings.ToUpper(method)
+	if method != "SYSTEM" && method != "BERNOULLI" {
+		panic("sqlbuilder.TableSample: invalid method: " + method)
+	}
+	if percentage < 0 || percentage > 100 {
+		panic("sqlbuilder.TableSample: invalid percentage")
+	}
+	q.pending.tableSample = " TABLESAMPLE " + method + " (" + strconv.FormatFloat(percentage, 'g', -1, 64) + ")"
+	return q
+}
+
+// Hint makes the next select statement emit mysql optimizer hint
+// "SELECT /*+ hint */", e.g. Hint("MAX_EXECUTION_TIME(1000)").
+// Comment delimiters are removed from hint.
+// Hint is a no-op for drivers other than mysql.
+func (q *Query) Hint(hint string) *Query {
+	if q.driver != "mysql" {
+		return q
+	}
+	for strings.Contains(hint, "*/") || strings.Contains(hint, "/*") {
+		hint = strings.ReplaceAll(hint, "*/", "")
+		hint = strings.ReplaceAll(hint, "/*", "")
+	}
+	q.pending.hint = strings.TrimSpace(hint)
+	return q
+}
+
+// Distinct makes the next select statement emit "SELECT DISTINCT".
+// Distinct replaces a pending DistinctOn.
+func (q *Query) Distinct() *Query {
+	q.pending.distinct = true
+	q.pending.distinctOn = nil
+	return q
+}
+
+// DistinctIf calls Distinct if ok is true, it doesn't change query otherwise.
+func (q *Query) DistinctIf(ok bool) *Query {
+	if ok {
+		return q.Distinct()
+	}
+	return q
+}
+
+// DistinctOn makes the next select statement emit "SELECT DISTINCT ON (columns)".
+// DistinctOn replaces a pending Distinct.
+//
+// DistinctOn panics if driver is not pg.
+func (q *Query) DistinctOn(columns ...string) *Query {
+	if q.driver != "pg" {
+		panic("sqlbuilder.DistinctOn: unsupported by driver: " + q.driver)
+	}
+	q.pending.distinct = false
+	q.pending.distinctOn = columns
 	return q
 }
 
@@ -82,31 +526,181 @@ func (q *Query) addColumns(columns ...string) {
 	for i, c := range columns {
 		q.str.WriteString(c)
 		if i != len(columns)-1 {
-			q.str.WriteByte(',')
+			q.addComma()
 		}
 	}
 }
 
 func (q *Query) addArg(arg interface{}) {
-	q.args = append(q.args, arg)
-	switch q.driver {
-	case "pg":
-		q.str.WriteByte('$')
-		q.str.WriteString(strconv.Itoa(len(q.args)))
-	case "mysql":
-		q.str.WriteByte('?')
+	if e, ok := arg.(Expression); ok {
+		q.Raw(e.sql, e.args...)
+		return
 	}
+	q.appendArgs(arg)
+	q.placeholder(q)
 }
 
-// addTables writes tables to query string, panics if tables length equal 0.
+// appendArgs appends args to query arguments,
+// sets query error if max arguments is exceeded.
+func (q *Query) appendArgs(args ...interface{}) {
+	if q.args == nil && q.argHint > 0 {
+		q.args = make([]interface{}, 0, q.argHint)
+	}
+	n := len(q.args)
+	q.args = append(q.args, args...)
+	for i := n; i < len(q.args); i++ {
+		q.args[i] = bindValue(q.args[i])
+	}
+	if q.maxArgs > 0 && len(q.args) > q.maxArgs {
+		q.setErr(fmt.Errorf("sqlbuilder: too many arguments: %d exceeds max of %d", len(q.args), q.maxArgs))
+	}
+}
+
+// basicTypes maps kinds to their predeclared types.
+var basicTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.String:  reflect.TypeOf(""),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+}
+
+// bindValue returns v converted to its underlying type if it's of a named basic type,
+// e.g. type Status string, so drivers checking argument types accept it.
+// Values implementing driver.Valuer are returned as is.
+func bindValue(v interface{}) interface{} {
+	switch v.(type) {
+	case nil, string, int, int64, float64, bool, []byte, time.Time, driver.Valuer, Expression:
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	if t, ok := basicTypes[rv.Kind()]; ok && rv.Type() != t {
+		return rv.Convert(t).Interface()
+	}
+	return v
+}
+
+// Trim removes trailing whitespace and semicolons from query string.
+func (q *Query) Trim() *Query {
+	str := q.str.String()
+	if n := len(strings.TrimRight(str, " \t\r\n;")); n != len(str) {
+		q.truncate(n)
+	}
+	return q
+}
+
+// truncate truncates query string to n bytes and returns the removed tail.
+func (q *Query) truncate(n int) string {
+	str := q.str.String()
+	q.str.Reset()
+	q.str.WriteString(str[:n])
+	return str[n:]
+}
+
+// embed writes sub query string with its rows cap to query and appends sub's arguments,
+// numbered placeholders of sub are renumbered to follow query arguments.
+func (q *Query) embed(sub *Query) {
+	q.embedString(sub, sub.str.String()+sub.rowsCap())
+}
+
+// embedString is like embed with str written in place of sub query string.
+func (q *Query) embedString(sub *Query, str string) {
+	if p := sub.dialect().prefix; p != "" && len(q.args) != 0 {
+		offset := len(q.args)
+		str = replacePlaceholders(str, p, func(n int) string {
+			return p + strconv.Itoa(n+offset)
+		})
+	}
+	q.str.WriteString(str)
+	q.appendArgs(sub.args...)
+	if sub.specific {
+		q.specific = true
+	}
+}
+
+// replacePlaceholders returns str with every placeholder outside of quotes
+// replaced by repl(n), n is the 1-based placeholder number.
+// prefix is the numbered placeholders prefix, e.g. "$",
+// or empty for '?' placeholders.
+func replacePlaceholders(str, prefix string, repl func(n int) string) string {
+	var b strings.Builder
+	var last, count int
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case '\'', '"', '`':
+			// skip quoted strings and identifiers.
+			if j := strings.IndexByte(str[i+1:], str[i]); j != -1 {
+				i += j + 1
+			}
+		case '?':
+			if prefix != "" {
+				continue
+			}
+			count++
+			b.WriteString(str[last:i])
+			b.WriteString(repl(count))
+			last = i + 1
+		default:
+			if prefix == "" || !strings.HasPrefix(str[i:], prefix) {
+				continue
+			}
+			start := i + len(prefix)
+			j := start
+			for j < len(str) && str[j] >= '0' && str[j] <= '9' {
+				j++
+			}
+			if j == start {
+				continue
+			}
+			n, _ := strconv.Atoi(str[start:j])
+			b.WriteString(str[last:i])
+			b.WriteString(repl(n))
+			last = j
+			i = j - 1
+		}
+	}
+	b.WriteString(str[last:])
+	return b.String()
+}
+
+// addTables writes tables to query string, panics if tables length equal 0
+// or pending Only is unsupported by the statement.
 func (q *Query) addTables() {
-	switch len(q.tables) {
-	case 0:
-		panic("sqlbuilder: tables cannot be empty")
-	case 1:
-		q.str.WriteString(q.tables[0])
-	default:
-		q.str.WriteString(strings.Join(q.tables, ","))
+	if q.mods.only {
+		q.mods.only = false
+		switch q.kind {
+		case KindSelect, KindUpdate, KindDelete, KindTruncate, KindMerge:
+			q.setSpecific()
+			q.str.WriteString("ONLY ")
+		default:
+			panic("sqlbuilder.Only: unsupported by statement")
+		}
+	}
+	if len(q.tables) == 0 {
+		q.setErr(errors.New("sqlbuilder: tables cannot be empty"))
+		return
+	}
+	for i, t := range q.tables {
+		if i != 0 {
+			q.addComma()
+		}
+		q.str.WriteString(t)
+		if i == 0 && q.mods.tableSample != "" {
+			if q.kind == KindSelect {
+				q.setSpecific()
+				q.str.WriteString(q.mods.tableSample)
+			}
+			q.mods.tableSample = ""
+		}
 	}
 }
 
@@ -117,113 +711,430 @@ func (q *Query) Statement() *Statement {
 
 // Select returns sql select statement.
 func (q *Query) Select(columns ...string) *Statement {
-	q.Reset()
+	q.begin(KindSelect)
 	q.str.WriteString("SELECT ")
+	q.addHint()
+	q.addDistinct()
 	if columns != nil {
 		q.addColumns(columns...)
 	} else {
 		q.str.WriteByte('*')
 	}
+	q.selectCols = len(columns)
+	q.selectList = columns
+	if q.mods.totalWindow {
+		q.addComma()
+		q.str.WriteString("COUNT(*) OVER() AS total")
+		q.mods.totalWindow = false
+	}
 	q.str.WriteString(" FROM ")
 	q.addTables()
 	return q.Statement()
 }
 
+// Count returns "SELECT COUNT(*) FROM tables" statement.
+func (q *Query) Count() *Statement {
+	return q.Select("COUNT(*)")
+}
+
+// SelectInto returns "SELECT columns INTO newTable FROM tables" statement
+// creating newTable from the query result.
+//
+// SelectInto panics if newTable is not a valid identifier
+// or if driver is not pg or mssql.
+func (q *Query) SelectInto(newTable string, columns ...string) *Statement {
+	if q.driver != "pg" && q.driver != "mssql" {
+		panic("sqlbuilder.SelectInto: unsupported by driver: " + q.driver)
+	}
+	if !isPlainName(newTable) {
+		panic("sqlbuilder.SelectInto: invalid table name: " + newTable)
+	}
+	q.begin(KindSelect)
+	q.setSpecific()
+	q.str.WriteString("SELECT ")
+	if columns != nil {
+		q.addColumns(columns...)
+	} else {
+		q.str.WriteByte('*')
+	}
+	q.str.WriteString(" INTO ")
+	q.str.WriteString(newTable)
+	q.str.WriteString(" FROM ")
+	q.addTables()
+	return q.Statement()
+}
+
+// SelectQualified returns sql select statement
+// with each column prefixed by "table.".
+func (q *Query) SelectQualified(table string, columns ...string) *Statement {
+	qualified := make([]string, len(columns))
+	for i, c := range columns {
+		qualified[i] = q.qualify(table, c)
+	}
+	return q.Select(qualified...)
+}
+
+// SelectDistinct returns sql select distinct statement.
+func (q *Query) SelectDistinct(columns ...string) *Statement {
+	return q.Distinct().Select(columns...)
+}
+
+// addHint writes pending mysql optimizer hint to query string.
+func (q *Query) addHint() {
+	if q.mods.hint != "" {
+		q.setSpecific()
+		q.str.WriteString("/*+ ")
+		q.str.WriteString(q.mods.hint)
+		q.str.WriteString(" */ ")
+		q.mods.hint = ""
+	}
+}
+
+// addDistinct writes pending distinct modifier to query string.
+func (q *Query) addDistinct() {
+	if q.mods.distinct {
+		q.str.WriteString("DISTINCT ")
+	} else if len(q.mods.distinctOn) > 0 {
+		q.setSpecific()
+		q.str.WriteString("DISTINCT ON (")
+		q.addColumns(q.mods.distinctOn...)
+		q.str.WriteString(") ")
+	}
+	q.mods.distinct = false
+	q.mods.distinctOn = nil
+}
+
+// SelectExists returns sql select exists statement
+// with sub as the subquery, sub's arguments are appended to query arguments.
+func (q *Query) SelectExists(sub *Statement) *Statement {
+	q.begin(KindSelect)
+	q.str.WriteString("SELECT EXISTS(")
+	q.embed(sub.Query)
+	q.str.WriteByte(')')
+	q.selectCols = 1
+	return q.Statement()
+}
+
 // Insert returns sql insert statement.
+// values of type Expression are written inline with their arguments.
 func (q *Query) Insert(columns []string, values ...interface{}) *Statement {
-	q.Reset()
+	if !isRow(values[0]) {
+		return q.insertRow(columns, values)
+	}
+	q.beginInsert(columns)
+	for i, vs := range values {
+		if i != 0 {
+			q.addComma()
+			q.str.WriteByte('(')
+		}
+		q.addRow(vs)
+		q.str.WriteByte(')')
+	}
+	return q.Statement()
+}
+
+// insertRow returns sql insert statement of a single row of values,
+// each value is written as an argument even if it's a slice.
+func (q *Query) insertRow(columns []string, values []interface{}) *Statement {
+	q.beginInsert(columns)
+	for i, v := range values {
+		if i != 0 {
+			q.addComma()
+		}
+		q.addArg(v)
+	}
+	q.str.WriteByte(')')
+	return q.Statement()
+}
+
+// beginInsert begins insert statement of columns up to its first values row.
+func (q *Query) beginInsert(columns []string) {
+	q.begin(KindInsert)
 	q.str.WriteString("INSERT INTO ")
 	q.addTables()
+	if q.pretty {
+		q.str.WriteByte(' ')
+	}
 	q.str.WriteByte('(')
 	q.addColumns(columns...)
-	q.str.WriteString(")VALUES(")
+	q.str.WriteString(") VALUES (")
+}
 
-	v := reflect.ValueOf(values[0])
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
-		for i, vs := range values {
-			if i != 0 {
-				v = reflect.ValueOf(vs)
-				if v.Kind() == reflect.Ptr {
-					v = v.Elem()
-				}
-				q.str.WriteByte('(')
-			}
-			for j := 0; j < v.Len(); j++ {
-				q.addArg(v.Index(j).Interface())
-				if j != v.Len()-1 {
-					q.str.WriteByte(',')
-				}
-			}
-			q.str.WriteByte(')')
-			if i != len(values)-1 {
-				q.str.WriteByte(',')
-			}
+// InsertPartial returns sql insert statement of data columns and values,
+// columns are sorted and columns not in data are left to their defaults.
+// InsertPartial with empty data inserts a row of defaults,
+// "DEFAULT VALUES" or "() VALUES ()" for mysql.
+func (q *Query) InsertPartial(data map[string]interface{}) *Statement {
+	if len(data) == 0 {
+		q.begin(KindInsert)
+		q.str.WriteString("INSERT INTO ")
+		q.addTables()
+		q.setSpecific()
+		if q.driver == "mysql" {
+			q.str.WriteString(" () VALUES ()")
+		} else {
+			q.str.WriteString(" DEFAULT VALUES")
 		}
 		return q.Statement()
 	}
+	columns := make([]string, 0, len(data))
+	for c := range data {
+		columns = append(columns, c)
+	}
+	sort.Strings(columns)
+	values := make([]interface{}, len(columns))
+	for i, c := range columns {
+		values[i] = data[c]
+	}
+	return q.insertRow(columns, values)
+}
 
-	for i, vs := range values {
-		q.addArg(vs)
-		if i != len(values)-1 {
-			q.str.WriteByte(',')
+// InsertSelect returns sql insert statement of the rows selected by sub,
+// e.g. "INSERT INTO t(a,b) SELECT a,b FROM s",
+// sub's arguments are appended to query arguments.
+// columns can be empty to insert every table column.
+// A following ON CONFLICT is preceded by "WHERE true" for sqlite if sub ends
+// with its tables, sqlite would parse ON CONFLICT as a join constraint.
+func (q *Query) InsertSelect(columns []string, sub *Statement) *Statement {
+	q.begin(KindInsert)
+	q.str.WriteString("INSERT INTO ")
+	q.addTables()
+	if len(columns) > 0 {
+		if q.pretty {
+			q.str.WriteByte(' ')
 		}
+		q.str.WriteByte('(')
+		q.addColumns(columns...)
+		q.str.WriteByte(')')
 	}
+	q.str.WriteByte(' ')
+	q.embed(sub.Query)
+	q.selectWhere = q.driver == "sqlite" && sub.kind == KindSelect &&
+		sub.clauses&clausesAfterWhere == 0 && !sub.HasWhere() && sub.rowsCap() == ""
+	return q.Statement()
+}
+
+// InsertIfNotExists returns sql insert statement of one row inserted only
+// if no row of query's table matches cond, e.g.
+// "INSERT INTO t(a,b) SELECT $1,$2 WHERE NOT EXISTS (SELECT 1 FROM t WHERE a = $3)",
+// it's an alternative to ON CONFLICT for drivers that don't support it.
+// values of type Expression are written inline with their arguments.
+func (q *Query) InsertIfNotExists(columns []string, values []interface{}, cond string, args ...interface{}) *Statement {
+	q.begin(KindInsert)
+	q.str.WriteString("INSERT INTO ")
+	q.addTables()
+	if q.pretty {
+		q.str.WriteByte(' ')
+	}
+	q.str.WriteByte('(')
+	q.addColumns(columns...)
+	q.str.WriteString(") SELECT ")
+	for i, v := range values {
+		if i != 0 {
+			q.addComma()
+		}
+		q.addArg(v)
+	}
+	if q.driver == "mysql" {
+		q.setSpecific()
+		q.str.WriteString(" FROM DUAL")
+	}
+	q.str.WriteString(" WHERE NOT EXISTS (SELECT 1 FROM ")
+	q.str.WriteString(q.Table())
+	q.str.WriteString(" WHERE ")
+	q.Raw(cond, args...)
 	q.str.WriteByte(')')
 	return q.Statement()
 }
 
+// isRow reports whether v is a row of insert values, a slice or an array,
+// []byte and driver.Valuer values are not rows.
+func isRow(v interface{}) bool {
+	switch v.(type) {
+	case []interface{}:
+		return true
+	case []byte, driver.Valuer:
+		return false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	return rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array
+}
+
+// addRow writes row values as arguments,
+// []interface{} rows are written without using reflection.
+func (q *Query) addRow(row interface{}) {
+	if vs, ok := row.([]interface{}); ok {
+		for i, v := range vs {
+			if i != 0 {
+				q.addComma()
+			}
+			q.addArg(v)
+		}
+		return
+	}
+	v := reflect.ValueOf(row)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	for i := 0; i < v.Len(); i++ {
+		if i != 0 {
+			q.addComma()
+		}
+		q.addArg(v.Index(i).Interface())
+	}
+}
+
 // Update returns sql update statement.
 // data type can be string or map[string]interface{}.
 // args is only used if data is a string.
+// map values of type Expression are written inline.
 func (q *Query) Update(data interface{}, args ...interface{}) *Statement {
-	q.Reset()
+	q.begin(KindUpdate)
 	q.str.WriteString("UPDATE ")
 	q.addTables()
 	q.str.WriteString(" SET ")
+	q.addSet("sqlbuilder.Update", data, args...)
+	return q.Statement()
+}
 
+// addSet writes update set data to query, panics if data type is unexpected.
+func (q *Query) addSet(caller string, data interface{}, args ...interface{}) {
 	switch d := data.(type) {
 	case string:
 		q.Raw(d, args...)
 	case map[string]interface{}:
 		i := len(d) - 1
 		for k, v := range d {
-			q.str.WriteString(k)
-			q.str.WriteByte('=')
-			q.addArg(v)
+			q.addAssign(k, v)
 			if i != 0 {
-				q.str.WriteByte(',')
+				q.addComma()
 			}
 			i--
 		}
 	default:
-		panic("sqlbuilder.Update: unexpected data type")
+		panic(caller + ": unexpected data type")
 	}
+}
 
-	return q.Statement()
+// addAssign writes "column=value" with value as argument.
+func (q *Query) addAssign(column string, value interface{}) {
+	q.str.WriteString(column)
+	if q.pretty {
+		q.str.WriteString(" = ")
+	} else {
+		q.str.WriteByte('=')
+	}
+	q.addArg(value)
 }
 
 // Delete returns sql delete statement.
 func (q *Query) Delete() *Statement {
-	q.Reset()
+	q.begin(KindDelete)
 	q.str.WriteString("DELETE FROM ")
 	q.addTables()
 	return q.Statement()
 }
 
+// TruncateOptions describes sql truncate statement options,
+// they're only supported by pg.
+type TruncateOptions struct {
+	RestartIdentity  bool
+	ContinueIdentity bool
+	Cascade          bool
+}
+
+// ConfirmCascade allows the next truncate statement to use the Cascade option,
+// which also truncates every table referencing query's tables.
+//
+// ConfirmCascade panics if driver is not pg.
+func (q *Query) ConfirmCascade() *Query {
+	if q.driver != "pg" {
+		panic("sqlbuilder.ConfirmCascade: unsupported by driver: " + q.driver)
+	}
+	q.pending.confirmCascade = true
+	return q
+}
+
+// Truncate returns sql truncate statement.
+// The Cascade option must be confirmed by ConfirmCascade,
+// otherwise it's not written and query error is set.
+//
+// Truncate panics if driver is sqlite, if both RestartIdentity and ContinueIdentity are set,
+// or if options are set and driver is not pg.
+func (q *Query) Truncate(opts TruncateOptions) *Statement {
+	if opts.RestartIdentity && opts.ContinueIdentity {
+		panic("sqlbuilder.Truncate: RestartIdentity and ContinueIdentity are mutually exclusive")
+	}
+	if q.driver == "sqlite" {
+		panic("sqlbuilder.Truncate: unsupported by driver: " + q.driver)
+	}
+	if q.driver != "pg" && (opts.RestartIdentity || opts.ContinueIdentity || opts.Cascade) {
+		panic("sqlbuilder.Truncate: options unsupported by driver: " + q.driver)
+	}
+
+	q.begin(KindTruncate)
+	q.setSpecific()
+	q.str.WriteString("TRUNCATE TABLE ")
+	q.addTables()
+	if opts.RestartIdentity {
+		q.str.WriteString(" RESTART IDENTITY")
+	}
+	if opts.ContinueIdentity {
+		q.str.WriteString(" CONTINUE IDENTITY")
+	}
+	if opts.Cascade {
+		if q.mods.confirmCascade {
+			q.str.WriteString(" CASCADE")
+		} else {
+			q.setErr(errors.New("sqlbuilder.Truncate: cascade must be confirmed by ConfirmCascade"))
+		}
+	}
+	return q.Statement()
+}
+
 // Raw wirtes raw string to query and appends args to query arguments.
+// args of type Expression are written inline in place of their placeholder.
 func (q *Query) Raw(str string, args ...interface{}) *Query {
-	if q.driver == "pg" {
+	q.writeRaw(str, args, q.dialect().prefix != "" || hasExpr(args))
+	return q
+}
+
+// RawPart is a raw sql fragment with its arguments.
+type RawPart struct {
+	SQL  string
+	Args []interface{}
+}
+
+// RawAll writes parts to query in order, it's equivalent to
+// calling Raw for each part but resolves the driver placeholders once
+// and grows query string once for all parts.
+func (q *Query) RawAll(parts ...RawPart) *Query {
+	n := 0
+	for _, p := range parts {
+		n += len(p.SQL)
+	}
+	q.str.Grow(n)
+
+	numbered := q.dialect().prefix != ""
+	for _, p := range parts {
+		q.writeRaw(p.SQL, p.Args, numbered || hasExpr(p.Args))
+	}
+	return q
+}
+
+// writeRaw writes str to query and appends args to query arguments,
+// if rewrite is set '?' placeholders are replaced by args written with addArg.
+func (q *Query) writeRaw(str string, args []interface{}, rewrite bool) {
+	if rewrite {
 		idx := strings.IndexByte(str, '?')
 		if idx != -1 {
 			var i, last int
 			for idx != -1 && i < len(args) {
 				q.str.WriteString(str[last : last+idx])
-				q.args = append(q.args, args[i])
-				q.str.WriteByte('$')
-				q.str.WriteString(strconv.Itoa(len(q.args)))
+				q.addArg(args[i])
 				i++
 				last += idx + 1
 				idx = strings.IndexByte(str[last:], '?')
@@ -231,17 +1142,160 @@ func (q *Query) Raw(str string, args ...interface{}) *Query {
 			if len(str) > last {
 				q.str.WriteString(str[last:])
 			}
-			return q
+			return
 		}
 	}
 
 	q.str.WriteString(str)
 	if args != nil {
-		q.args = append(q.args, args...)
+		q.appendArgs(args...)
 	}
+}
+
+// RawNamed is like Raw but str can mix '?' positional and ":name" named placeholders,
+// positional ones take args in order and named ones take their value from named.
+// Placeholders are numbered left to right whatever their kind,
+// a named placeholder used twice binds its value twice.
+// Placeholders inside quotes and pg "::" casts are left as is.
+//
+// RawNamed sets query error if a name is missing from named
+// or the number of args differs from the number of positional placeholders.
+func (q *Query) RawNamed(str string, named map[string]interface{}, args ...interface{}) *Query {
+	var i, last int
+	for j := 0; j < len(str); j++ {
+		switch c := str[j]; c {
+		case '\'', '"', '`':
+			if k := strings.IndexByte(str[j+1:], c); k != -1 {
+				j += k + 1
+			}
+		case '?':
+			q.str.WriteString(str[last:j])
+			last = j + 1
+			if i >= len(args) {
+				q.setErr(fmt.Errorf("sqlbuilder.RawNamed: missing argument %d", i+1))
+				continue
+			}
+			q.addArg(args[i])
+			i++
+		case ':':
+			if j+1 < len(str) && str[j+1] == ':' {
+				j++
+				continue
+			}
+			n := j + 1
+			for n < len(str) && isIdentByte(str[n], n == j+1) {
+				n++
+			}
+			if n == j+1 {
+				continue
+			}
+			q.str.WriteString(str[last:j])
+			last = n
+			name := str[j+1 : n]
+			j = n - 1
+			v, ok := named[name]
+			if !ok {
+				q.setErr(fmt.Errorf("sqlbuilder.RawNamed: missing named argument %q", name))
+				continue
+			}
+			q.addArg(v)
+		}
+	}
+	q.str.WriteString(str[last:])
+	if i < len(args) {
+		q.setErr(fmt.Errorf("sqlbuilder.RawNamed: %d unused arguments", len(args)-i))
+	}
+	return q
+}
+
+// isIdentByte reports whether c can be part of an identifier,
+// digits are not allowed as the first byte.
+func isIdentByte(c byte, first bool) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || !first && c >= '0' && c <= '9'
+}
+
+// Placeholders returns n comma separated placeholders numbered
+// after the current query arguments, e.g. "$3,$4" for pg or "?,?" for mysql.
+// It doesn't append any arguments.
+func (q *Query) Placeholders(n int) string {
+	p := q.dialect().prefix
+	if p == "" && !q.pretty {
+		return placeholders(n)
+	}
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if i != 1 {
+			b.WriteString(q.comma())
+		}
+		if p == "" {
+			b.WriteByte('?')
+			continue
+		}
+		b.WriteString(p)
+		b.WriteString(strconv.Itoa(len(q.args) + i))
+	}
+	return b.String()
+}
+
+// CheckRaw returns an error if the number of '?' placeholders
+// in str doesn't match the number of args.
+func CheckRaw(str string, args ...interface{}) error {
+	if n := strings.Count(str, "?"); n != len(args) {
+		return fmt.Errorf("sqlbuilder.CheckRaw: %d placeholders, %d arguments", n, len(args))
+	}
+	return nil
+}
+
+func hasExpr(args []interface{}) bool {
+	for _, arg := range args {
+		if _, ok := arg.(Expression); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RawSpace is like Raw but ensures str is separated from query string by one space,
+// no space is added if query string is empty or ends with whitespace or '('.
+func (q *Query) RawSpace(str string, args ...interface{}) *Query {
+	str = strings.TrimLeft(str, " \t\n")
+	if n := q.str.Len(); n > 0 {
+		switch q.str.String()[n-1] {
+		case ' ', '\t', '\n', '(':
+		default:
+			q.str.WriteByte(' ')
+		}
+	}
+	return q.Raw(str, args...)
+}
+
+// RawParen is like Raw but wraps str in parentheses.
+func (q *Query) RawParen(str string, args ...interface{}) *Query {
+	q.str.WriteByte('(')
+	q.Raw(str, args...)
+	q.str.WriteByte(')')
+	return q
+}
+
+// ScalarSub writes sub wrapped in parentheses to query as a scalar sub query,
+// sub's arguments are appended to query arguments.
+//
+// ScalarSub sets query error if sub selects more than one column.
+func (q *Query) ScalarSub(sub *Statement) *Query {
+	q.checkScalar("sqlbuilder.ScalarSub", sub)
+	q.str.WriteByte('(')
+	q.embed(sub.Query)
+	q.str.WriteByte(')')
 	return q
 }
 
+// checkScalar sets query error if sub selects more than one column.
+func (q *Query) checkScalar(caller string, sub *Statement) {
+	if sub.kind == KindSelect && sub.selectCols > 1 {
+		q.setErr(fmt.Errorf("%s: sub query selects %d columns, want 1", caller, sub.selectCols))
+	}
+}
+
 // RawByte writes byte to query.
 func (q *Query) RawByte(b byte) *Query {
 	q.str.WriteByte(b)
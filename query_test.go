@@ -1,6 +1,11 @@
 package sqlbuilder
 
-import "testing"
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestSelect(t *testing.T) {
 	q := NewQuery("test")
@@ -43,7 +48,7 @@ func TestInsert(t *testing.T) {
 	q.Insert([]string{"t1", "t2", "t3"}, 50, -100, "v1")
 
 	gotStr := q.String()
-	wantStr := "INSERT INTO test(t1,t2,t3)VALUES($1,$2,$3)"
+	wantStr := "INSERT INTO test(t1,t2,t3) VALUES ($1,$2,$3)"
 	gotArgs := q.Args()
 	wantArgs := []interface{}{50, -100, "v1"}
 
@@ -67,7 +72,7 @@ func TestInsert(t *testing.T) {
 	)
 
 	gotStr = q.String()
-	wantStr = "INSERT INTO test2(t1,t2,t3)VALUES($1,$2,$3),($4,$5,$6)"
+	wantStr = "INSERT INTO test2(t1,t2,t3) VALUES ($1,$2,$3),($4,$5,$6)"
 	gotArgs = q.Args()
 	wantArgs = []interface{}{"12", 10, "abcdefg", "hijk", uint8(1), 21.12}
 
@@ -175,3 +180,2130 @@ func TestRaw(t *testing.T) {
 		}
 	}
 }
+
+func TestRawAll(t *testing.T) {
+	for _, driver := range []string{"pg", "mysql"} {
+		parts := []RawPart{
+			{SQL: "SELECT * FROM test WHERE a = ?", Args: []interface{}{1}},
+			{SQL: " AND b IN (?,?)", Args: []interface{}{"x", "y"}},
+			{SQL: " AND c = ?", Args: []interface{}{Expr("NOW()")}},
+			{SQL: " LIMIT 10"},
+		}
+
+		q := NewQuery("test").SetDriver(driver).RawAll(parts...)
+		seq := NewQuery("test").SetDriver(driver)
+		for _, p := range parts {
+			seq.Raw(p.SQL, p.Args...)
+		}
+
+		gotStr := q.String()
+		wantStr := seq.String()
+		gotArgs := q.Args()
+		wantArgs := seq.Args()
+
+		if gotStr != wantStr {
+			t.Errorf("RawAll %s string: want %q, got %q", driver, wantStr, gotStr)
+		}
+		if len(gotArgs) != len(wantArgs) {
+			t.Errorf("RawAll %s arguments length: want %d, got %d", driver, len(wantArgs), len(gotArgs))
+		}
+		for i, v := range gotArgs {
+			if v != wantArgs[i] {
+				t.Errorf("RawAll %s arguments[%d]: want %v, got %v", driver, i, wantArgs[i], v)
+			}
+		}
+	}
+}
+
+func TestSelectExists(t *testing.T) {
+	sub := NewQuery("orders").Select("1").Where("user_id = ? AND total > ?", 7, 100)
+	q := NewQuery("users")
+	q.SelectExists(sub)
+
+	gotStr := q.String()
+	wantStr := "SELECT EXISTS(SELECT 1 FROM orders WHERE user_id = $1 AND total > $2)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{7, 100}
+
+	if gotStr != wantStr {
+		t.Errorf("SelectExists string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("SelectExists arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("SelectExists arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+}
+
+func TestRawParen(t *testing.T) {
+	q := NewQuery("test")
+	q.Select().Where("active = ? AND ", true).RawParen("role = ? OR role = ?", "admin", "owner")
+
+	gotStr := q.String()
+	wantStr := "SELECT * FROM test WHERE active = $1 AND (role = $2 OR role = $3)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{true, "admin", "owner"}
+
+	if gotStr != wantStr {
+		t.Errorf("RawParen string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("RawParen arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("RawParen arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+}
+
+func TestInsertExpr(t *testing.T) {
+	q := NewQuery("test")
+	q.Insert(
+		[]string{"t1", "t2", "t3"},
+		[]interface{}{"v1", Expr("now()"), 1},
+		[]interface{}{Expr("DEFAULT"), Expr("now()"), 2},
+	)
+
+	gotStr := q.String()
+	wantStr := "INSERT INTO test(t1,t2,t3) VALUES ($1,now(),$2),(DEFAULT,now(),$3)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{"v1", 1, 2}
+
+	if gotStr != wantStr {
+		t.Errorf("Insert with Expr string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Insert with Expr arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Insert with Expr arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+}
+
+func TestExpr(t *testing.T) {
+	q := NewQuery("test")
+	q.Update(map[string]interface{}{"v": Expr("v + ?", 1)}).Where(Eq("id", Expr("ANY(?)", "{1,2}")))
+
+	gotStr := q.String()
+	wantStr := "UPDATE test SET v=v + $1 WHERE id = ANY($2)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{1, "{1,2}"}
+
+	if gotStr != wantStr {
+		t.Errorf("Update with Expr string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Update with Expr arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Update with Expr arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q = NewQuery("test").SetDriver("mysql")
+	q.Insert([]string{"t1", "t2"}, "v1", Expr("UPPER(?)", "v2"))
+
+	gotStr = q.String()
+	wantStr = "INSERT INTO test(t1,t2) VALUES (?,UPPER(?))"
+	gotArgs = q.Args()
+	wantArgs = []interface{}{"v1", "v2"}
+
+	if gotStr != wantStr {
+		t.Errorf("Insert with Expr string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Insert with Expr arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Insert with Expr arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+}
+
+func TestWhereOpAll(t *testing.T) {
+	sub := NewQuery("employees").Select("salary").Where("department = ?", "sales")
+	q := NewQuery("employees")
+	q.Update("bonus = ?", 500).WhereOpAll("salary", ">", sub)
+
+	gotStr := q.String()
+	wantStr := "UPDATE employees SET bonus = $1 WHERE salary > ALL(SELECT salary FROM employees WHERE department = $2)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{500, "sales"}
+
+	if gotStr != wantStr {
+		t.Errorf("WhereOpAll string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("WhereOpAll arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("WhereOpAll arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q.Select().WhereOpAny("id", "=", NewQuery("admins").Select("user_id"))
+
+	gotStr = q.String()
+	wantStr = "SELECT * FROM employees WHERE id = ANY(SELECT user_id FROM admins)"
+
+	if gotStr != wantStr {
+		t.Errorf("WhereOpAny string: want %q, got %q", wantStr, gotStr)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("WhereOpAny with invalid operator: want panic")
+		}
+	}()
+	q.Select().WhereOpAny("id", "= 1 OR 1 =", sub)
+}
+
+func TestGroupByPos(t *testing.T) {
+	q := NewQuery("test")
+	q.Select("country", "city", "COUNT(*)").GroupByPos(1, 2)
+
+	gotStr := q.String()
+	wantStr := "SELECT country,city,COUNT(*) FROM test GROUP BY 1,2"
+
+	if gotStr != wantStr {
+		t.Errorf("GroupByPos string: want %q, got %q", wantStr, gotStr)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("GroupByPos with position 0: want panic")
+		}
+	}()
+	q.Select().GroupByPos(0)
+}
+
+func TestCheckRaw(t *testing.T) {
+	if err := CheckRaw("id = ? AND name = ?", 1, "v"); err != nil {
+		t.Errorf("CheckRaw: want <nil>, got %v", err)
+	}
+	if err := CheckRaw("id = ? AND name = ?", 1); err == nil {
+		t.Errorf("CheckRaw with missing argument: want error, got <nil>")
+	}
+	if err := CheckRaw("id = ?", 1, 2); err == nil {
+		t.Errorf("CheckRaw with extra argument: want error, got <nil>")
+	}
+}
+
+func TestUpdateOrderByLimit(t *testing.T) {
+	q := NewQuery("test").SetDriver("mysql")
+	q.Update("processed = ?", true).Where("processed = ?", false).OrderBy("id").Limit(100)
+
+	gotStr := q.String()
+	wantStr := "UPDATE test SET processed = ? WHERE processed = ? ORDER BY id LIMIT ?"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{true, false, 100}
+
+	if gotStr != wantStr {
+		t.Errorf("Update with Limit string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Update with Limit arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Update with Limit arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Update with Limit on pg: want panic")
+		}
+	}()
+	NewQuery("test").Update("processed = ?", true).Limit(100)
+}
+
+func TestDeleteOrderByLimit(t *testing.T) {
+	q := NewQuery("logs").SetDriver("mysql")
+	q.Delete().Where("created_at < ?", "2020-01-01").OrderBy("created_at").Limit(1000)
+
+	gotStr := q.String()
+	wantStr := "DELETE FROM logs WHERE created_at < ? ORDER BY created_at LIMIT ?"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{"2020-01-01", 1000}
+
+	if gotStr != wantStr {
+		t.Errorf("Delete with Limit string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Delete with Limit arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Delete with Limit arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Delete with OrderBy on pg: want panic")
+		}
+	}()
+	NewQuery("logs").Delete().OrderBy("created_at")
+}
+
+func TestOnly(t *testing.T) {
+	q := NewQuery("measurements")
+	q.Only().Select("id").Where("id = ?", 1)
+
+	gotStr := q.String()
+	wantStr := "SELECT id FROM ONLY measurements WHERE id = $1"
+
+	if gotStr != wantStr {
+		t.Errorf("Only Select string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.Only().Update("v = ?", 2)
+
+	gotStr = q.String()
+	wantStr = "UPDATE ONLY measurements SET v = $1"
+
+	if gotStr != wantStr {
+		t.Errorf("Only Update string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.Delete()
+
+	gotStr = q.String()
+	wantStr = "DELETE FROM measurements"
+
+	if gotStr != wantStr {
+		t.Errorf("Delete after Only string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.Only().Truncate(TruncateOptions{})
+
+	gotStr = q.String()
+	wantStr = "TRUNCATE TABLE ONLY measurements"
+
+	if gotStr != wantStr {
+		t.Errorf("Only Truncate string: want %q, got %q", wantStr, gotStr)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Only DropTable: want panic")
+			}
+		}()
+		q.Only().Confirm().DropTable(false, false)
+	}()
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Only on mysql: want panic")
+		}
+	}()
+	NewQuery("measurements").SetDriver("mysql").Only()
+}
+
+func TestReset(t *testing.T) {
+	q := NewQuery("test")
+	q.Update("v = ?", 1).Where("id = ?", 2)
+	q.Only().Reset()
+
+	if got := q.String(); got != "" {
+		t.Errorf("Reset string: want empty, got %q", got)
+	}
+	if got := q.Args(); got != nil {
+		t.Errorf("Reset arguments: want <nil>, got %v", got)
+	}
+
+	q.Select().Limit(1)
+
+	gotStr := q.String()
+	wantStr := "SELECT * FROM test LIMIT $1"
+
+	if gotStr != wantStr {
+		t.Errorf("Select after Reset string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestSelectDistinct(t *testing.T) {
+	q := NewQuery("orders")
+	q.SelectDistinct("customer_id", "SUM(total)").GroupBy("customer_id")
+
+	gotStr := q.String()
+	wantStr := "SELECT DISTINCT customer_id,SUM(total) FROM orders GROUP BY customer_id"
+
+	if gotStr != wantStr {
+		t.Errorf("SelectDistinct string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.Distinct().DistinctOn("customer_id").Select("customer_id", "total").OrderByDesc("customer_id", "total")
+
+	gotStr = q.String()
+	wantStr = "SELECT DISTINCT ON (customer_id) customer_id,total FROM orders ORDER BY customer_id,total DESC"
+
+	if gotStr != wantStr {
+		t.Errorf("DistinctOn string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.Select("id")
+
+	gotStr = q.String()
+	wantStr = "SELECT id FROM orders"
+
+	if gotStr != wantStr {
+		t.Errorf("Select after Distinct string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestPretty(t *testing.T) {
+	q := NewQuery("test")
+	q.Insert([]string{"t1", "t2"}, []interface{}{1, 2}, []interface{}{3, 4})
+
+	gotStr := q.String()
+	wantStr := "INSERT INTO test(t1,t2) VALUES ($1,$2),($3,$4)"
+
+	if gotStr != wantStr {
+		t.Errorf("Insert compact string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.SetPretty(true)
+	q.Insert([]string{"t1", "t2"}, []interface{}{1, 2}, []interface{}{3, 4})
+
+	gotStr = q.String()
+	wantStr = "INSERT INTO test (t1, t2) VALUES ($1, $2), ($3, $4)"
+
+	if gotStr != wantStr {
+		t.Errorf("Insert pretty string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestJoin(t *testing.T) {
+	q := NewQuery("users u")
+	q.Select("u.name", "o.total").Join("orders o", "o.user_id = u.id AND o.total > ?", 10).LeftJoin("notes n", "n.user_id = u.id").Where("u.id = ?", 1)
+
+	gotStr := q.String()
+	wantStr := "SELECT u.name,o.total FROM users u JOIN orders o ON o.user_id = u.id AND o.total > $1 LEFT JOIN notes n ON n.user_id = u.id WHERE u.id = $2"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{10, 1}
+
+	if gotStr != wantStr {
+		t.Errorf("Join string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Join arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Join arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+}
+
+func TestJoinSeriesLateral(t *testing.T) {
+	q := NewQuery("events e")
+	q.Select("s.t", "COUNT(e.id)").JoinSeriesLateral("2020-01-01", "2020-01-31", "1 day", "s").GroupBy("s.t")
+
+	gotStr := q.String()
+	wantStr := "SELECT s.t,COUNT(e.id) FROM events e CROSS JOIN LATERAL generate_series($1,$2,$3) AS s(t) GROUP BY s.t"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{"2020-01-01", "2020-01-31", "1 day"}
+
+	if gotStr != wantStr {
+		t.Errorf("JoinSeriesLateral string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("JoinSeriesLateral arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("JoinSeriesLateral arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		opts TruncateOptions
+		want string
+	}{
+		{TruncateOptions{}, "TRUNCATE TABLE test"},
+		{TruncateOptions{Cascade: true}, "TRUNCATE TABLE test CASCADE"},
+		{TruncateOptions{RestartIdentity: true}, "TRUNCATE TABLE test RESTART IDENTITY"},
+		{TruncateOptions{ContinueIdentity: true}, "TRUNCATE TABLE test CONTINUE IDENTITY"},
+		{TruncateOptions{RestartIdentity: true, Cascade: true}, "TRUNCATE TABLE test RESTART IDENTITY CASCADE"},
+	}
+	q := NewQuery("test")
+	for _, tt := range tests {
+		if got := q.ConfirmCascade().Truncate(tt.opts).String(); got != tt.want {
+			t.Errorf("Truncate(%+v) string: want %q, got %q", tt.opts, tt.want, got)
+		}
+	}
+
+	if got, want := NewQuery("test").SetDriver("mysql").Truncate(TruncateOptions{}).String(), "TRUNCATE TABLE test"; got != want {
+		t.Errorf("Truncate mysql string: want %q, got %q", want, got)
+	}
+
+	panics := []struct {
+		driver string
+		opts   TruncateOptions
+	}{
+		{"pg", TruncateOptions{RestartIdentity: true, ContinueIdentity: true}},
+		{"mysql", TruncateOptions{Cascade: true}},
+	}
+	for _, tt := range panics {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Truncate(%+v) on %s: want panic", tt.opts, tt.driver)
+				}
+			}()
+			NewQuery("test").SetDriver(tt.driver).Truncate(tt.opts)
+		}()
+	}
+}
+
+func TestDriver(t *testing.T) {
+	q := NewQuery("test")
+	if got := q.Driver(); got != "pg" {
+		t.Errorf("Driver default: want %q, got %q", "pg", got)
+	}
+	if got := q.SetDriver("MySQL").Driver(); got != "mysql" {
+		t.Errorf("Driver after SetDriver: want %q, got %q", "mysql", got)
+	}
+	if got := q.SetDriver("postgres").Driver(); got != "pg" {
+		t.Errorf("Driver after SetDriver: want %q, got %q", "pg", got)
+	}
+}
+
+func TestSelectQualified(t *testing.T) {
+	q := NewQuery("users")
+	q.SelectQualified("users", "id", "name", "first name").Join("orders", "orders.user_id = users.id")
+
+	gotStr := q.String()
+	wantStr := `SELECT users.id,users.name,users."first name" FROM users JOIN orders ON orders.user_id = users.id`
+
+	if gotStr != wantStr {
+		t.Errorf("SelectQualified string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.SetDriver("mysql").SelectQualified("users", "*")
+
+	gotStr = q.String()
+	wantStr = "SELECT users.* FROM users"
+
+	if gotStr != wantStr {
+		t.Errorf("SelectQualified mysql string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestMaxArgs(t *testing.T) {
+	q := NewQuery("test").SetMaxArgs(3)
+	q.Insert([]string{"t1", "t2"}, []interface{}{1, 2}, []interface{}{3, 4})
+
+	if q.Err() == nil {
+		t.Errorf("Insert exceeding max arguments: want error, got <nil>")
+	}
+
+	q.Insert([]string{"t1", "t2"}, 1, 2)
+
+	if err := q.Err(); err != nil {
+		t.Errorf("Insert within max arguments: want <nil>, got %v", err)
+	}
+
+	q.SetMaxArgs(1).Select().Raw(" WHERE a = ? AND b = ?", 1, 2)
+
+	if q.Err() == nil {
+		t.Errorf("Raw exceeding max arguments: want error, got <nil>")
+	}
+}
+
+func TestOnTable(t *testing.T) {
+	q := NewQuery("users").SetDriver("mysql")
+	q.Distinct().OnTable("tenant1_users").Select("name").Where("id = ?", 1)
+
+	gotStr := q.String()
+	wantStr := "SELECT DISTINCT name FROM tenant1_users WHERE id = ?"
+
+	if gotStr != wantStr {
+		t.Errorf("OnTable string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q = NewQuery()
+	q.OnTable("users").Delete()
+
+	gotStr = q.String()
+	wantStr = "DELETE FROM users"
+
+	if gotStr != wantStr {
+		t.Errorf("OnTable without tables string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestSelectInto(t *testing.T) {
+	q := NewQuery("orders")
+	q.SelectInto("orders_2020", "id", "total").Where("created_at < ?", "2021-01-01")
+
+	gotStr := q.String()
+	wantStr := "SELECT id,total INTO orders_2020 FROM orders WHERE created_at < $1"
+
+	if gotStr != wantStr {
+		t.Errorf("SelectInto string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.SetDriver("mssql").SelectInto("dbo.orders_2020").Where("created_at < ? AND total > ?", "2021-01-01", 10)
+
+	gotStr = q.String()
+	wantStr = "SELECT * INTO dbo.orders_2020 FROM orders WHERE created_at < @p1 AND total > @p2"
+
+	if gotStr != wantStr {
+		t.Errorf("SelectInto mssql string: want %q, got %q", wantStr, gotStr)
+	}
+
+	for _, tt := range []struct{ driver, table string }{
+		{"mysql", "orders_2020"},
+		{"pg", "orders; DROP TABLE orders"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("SelectInto(%q) on %s: want panic", tt.table, tt.driver)
+				}
+			}()
+			NewQuery("orders").SetDriver(tt.driver).SelectInto(tt.table)
+		}()
+	}
+}
+
+func TestWhereDate(t *testing.T) {
+	q := NewQuery("events")
+	q.Select().WhereDate("created_at", ">=", "2020-05-01")
+
+	gotStr := q.String()
+	wantStr := "SELECT * FROM events WHERE created_at::date >= $1"
+	gotArgs := q.Args()
+
+	if gotStr != wantStr {
+		t.Errorf("WhereDate string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != 1 || gotArgs[0] != "2020-05-01" {
+		t.Errorf("WhereDate arguments: want [2020-05-01], got %v", gotArgs)
+	}
+
+	q.SetDriver("mysql").Select().WhereDate("created_at", "=", "2020-05-01")
+
+	gotStr = q.String()
+	wantStr = "SELECT * FROM events WHERE DATE(created_at) = ?"
+
+	if gotStr != wantStr {
+		t.Errorf("WhereDate mysql string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestUnion(t *testing.T) {
+	sub := NewQuery("bans").SetDriver("mysql").Select("user_id").Where("reason = ?", "spam")
+	other := NewQuery("archived_users").SetDriver("mysql").Select("id", "name").Where("age > ?", 30)
+	q := NewQuery("users").SetDriver("mysql")
+	q.Select("id", "name").WhereOpAll("id", "<>", sub).Raw(" AND age > ?", 18).Statement().UnionAll(other)
+
+	gotStr := q.String()
+	wantStr := "(SELECT id,name FROM users WHERE id <> ALL(SELECT user_id FROM bans WHERE reason = ?) AND age > ?) UNION ALL (SELECT id,name FROM archived_users WHERE age > ?)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{"spam", 18, 30}
+
+	if gotStr != wantStr {
+		t.Errorf("Union mysql string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Union mysql arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Union mysql arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q = NewQuery("users")
+	q.Select("id").Where("age > ?", 18).Union(NewQuery("admins").Select("id").Where("level > ?", 2))
+
+	gotStr = q.String()
+	wantStr = "(SELECT id FROM users WHERE age > $1) UNION (SELECT id FROM admins WHERE level > $2)"
+
+	if gotStr != wantStr {
+		t.Errorf("Union string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q = NewQuery("users").SetDriver("sqlite")
+	q.Select("id").Union(NewQuery("admins").SetDriver("sqlite").Select("id"))
+
+	gotStr = q.String()
+	wantStr = "SELECT id FROM users UNION SELECT id FROM admins"
+
+	if gotStr != wantStr {
+		t.Errorf("Union sqlite string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestUnionOrderLimit(t *testing.T) {
+	recent := NewQuery("posts").Select("id", "created_at").OrderByDesc("created_at").Limit(5)
+	pinned := NewQuery("pinned_posts").Select("id", "created_at").Where("active = ?", true)
+	third := NewQuery("drafts").Select("id", "created_at")
+
+	s := recent.UnionAll(pinned).Union(third)
+	if s.HasOrderBy() || s.HasLimit() {
+		t.Errorf("Union clauses: want no order by and limit")
+	}
+	s.OrderBy("created_at").Limit(10)
+
+	gotStr := s.String()
+	wantStr := "(SELECT id,created_at FROM posts ORDER BY created_at DESC LIMIT $1) UNION ALL (SELECT id,created_at FROM pinned_posts WHERE active = $2) UNION (SELECT id,created_at FROM drafts) ORDER BY created_at LIMIT $3"
+	gotArgs := s.Args()
+	wantArgs := []interface{}{5, true, 10}
+
+	if gotStr != wantStr {
+		t.Errorf("Union order by limit string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Union order by limit arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Union order by limit arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+}
+
+func TestMust(t *testing.T) {
+	q := NewQuery("test")
+	if got := q.Select().Where("id = ?", 1).Must(); got.String() != "SELECT * FROM test WHERE id = $1" {
+		t.Errorf("Must string: want %q, got %q", "SELECT * FROM test WHERE id = $1", got.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Must with error: want panic")
+		}
+	}()
+	q.SetMaxArgs(1).Select().Where("a = ? AND b = ?", 1, 2).Must()
+}
+
+func TestReturningExpr(t *testing.T) {
+	q := NewQuery("sessions")
+	q.Update("seen_at = now()").Where("id = ?", 1).Returning("id", "created_at", As("now() - created_at", "age"))
+
+	gotStr := q.String()
+	wantStr := "UPDATE sessions SET seen_at = now() WHERE id = $1 RETURNING id,created_at,now() - created_at AS age"
+
+	if gotStr != wantStr {
+		t.Errorf("Returning with expression string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestWhereTSVector(t *testing.T) {
+	q := NewQuery("docs")
+	q.Select("id").WhereTSVector("search", "cat & dog", "english")
+
+	gotStr := q.String()
+	wantStr := "SELECT id FROM docs WHERE search @@ to_tsquery($1,$2)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{"english", "cat & dog"}
+
+	if gotStr != wantStr {
+		t.Errorf("WhereTSVector string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("WhereTSVector arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("WhereTSVector arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q.Select("id").WhereTSVector("search", "cat", "")
+
+	gotStr = q.String()
+	wantStr = "SELECT id FROM docs WHERE search @@ to_tsquery($1)"
+
+	if gotStr != wantStr {
+		t.Errorf("WhereTSVector without config string: want %q, got %q", wantStr, gotStr)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("WhereTSVector on mysql: want panic")
+		}
+	}()
+	NewQuery("docs").SetDriver("mysql").Select().WhereTSVector("search", "cat", "")
+}
+
+func TestOffset(t *testing.T) {
+	q := NewQuery("test")
+	q.Select().OrderBy("id").Offset(20)
+
+	gotStr := q.String()
+	wantStr := "SELECT * FROM test ORDER BY id OFFSET $1"
+
+	if gotStr != wantStr {
+		t.Errorf("Offset without Limit string: want %q, got %q", wantStr, gotStr)
+	}
+
+	q.Select().OrderBy("id").LimitAll().Offset(20)
+
+	gotStr = q.String()
+	wantStr = "SELECT * FROM test ORDER BY id LIMIT ALL OFFSET $1"
+	gotArgs := q.Args()
+
+	if gotStr != wantStr {
+		t.Errorf("LimitAll string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != 1 || gotArgs[0] != 20 {
+		t.Errorf("LimitAll arguments: want [20], got %v", gotArgs)
+	}
+
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{"mysql", "SELECT * FROM test ORDER BY id LIMIT 18446744073709551615 OFFSET ?"},
+		{"sqlite", "SELECT * FROM test ORDER BY id LIMIT -1 OFFSET ?"},
+		{"mssql", "SELECT * FROM test ORDER BY id OFFSET @p1 ROWS"},
+	}
+	for _, tt := range tests {
+		if got := NewQuery("test").SetDriver(tt.driver).Select().OrderBy("id").Offset(20).String(); got != tt.want {
+			t.Errorf("Offset without Limit %s string: want %q, got %q", tt.driver, tt.want, got)
+		}
+	}
+	if got, want := NewQuery("test").SetDriver("mssql").Select().Offset(20).String(), "SELECT * FROM test ORDER BY (SELECT NULL) OFFSET @p1 ROWS"; got != want {
+		t.Errorf("Offset without order by mssql string: want %q, got %q", want, got)
+	}
+	if got, want := NewQuery("test").SetDriver("mysql").Select().Limit(10).Offset(20).String(), "SELECT * FROM test LIMIT ? OFFSET ?"; got != want {
+		t.Errorf("Limit Offset mysql string: want %q, got %q", want, got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Limit after Offset on sqlite: want panic")
+		}
+	}()
+	NewQuery("test").SetDriver("sqlite").Select().Offset(20).Limit(10)
+}
+
+func TestLimitMSSQL(t *testing.T) {
+	q := func() *Query { return NewQuery("test").SetDriver("mssql") }
+
+	tests := []struct {
+		name string
+		s    *Statement
+		want string
+	}{
+		{"Limit", q().Select().OrderBy("id").Limit(10), "SELECT * FROM test ORDER BY id OFFSET 0 ROWS FETCH NEXT @p1 ROWS ONLY"},
+		{"Limit without order by", q().Select().Limit(10), "SELECT * FROM test ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT @p1 ROWS ONLY"},
+		{"Limit Offset", q().Select().OrderBy("id").Limit(10).Offset(20), "SELECT * FROM test ORDER BY id OFFSET @p2 ROWS FETCH NEXT @p1 ROWS ONLY"},
+		{"Offset Limit", q().Select().OrderBy("id").Offset(20).Limit(10), "SELECT * FROM test ORDER BY id OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY"},
+	}
+	for _, tt := range tests {
+		if got := tt.s.String(); got != tt.want {
+			t.Errorf("%s mssql string: want %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestPlaceholderCount(t *testing.T) {
+	tests := []*Statement{
+		NewQuery("test").Select(),
+		NewQuery("test").Select().Where("id = ? AND name = '?'", 1),
+		NewQuery("test").Insert([]string{"a", "b"}, []interface{}{1, 2}, []interface{}{3, 4}),
+		NewQuery("test").SetDriver("mysql").Update("a = ?", 1).Where(In("id", []int{1, 2, 3})),
+		NewQuery("test").SetDriver("mssql").Delete().Where("id = ? OR id = ?", 1, 2),
+	}
+	for _, s := range tests {
+		if got, want := s.PlaceholderCount(), len(s.Args()); got != want {
+			t.Errorf("PlaceholderCount(%q): want %d, got %d", s.String(), want, got)
+		}
+	}
+}
+
+func TestRawSpace(t *testing.T) {
+	q := NewQuery("test")
+	q.Select().RawSpace("WHERE id = ?", 1).RawSpace("  AND (").RawSpace("a = ? OR b = ?)", 2, 3).RawSpace(" LIMIT 1")
+
+	gotStr := q.String()
+	wantStr := "SELECT * FROM test WHERE id = $1 AND (a = $2 OR b = $3) LIMIT 1"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{1, 2, 3}
+
+	if gotStr != wantStr {
+		t.Errorf("RawSpace string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("RawSpace arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("RawSpace arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q.Reset().RawSpace("SELECT 1")
+
+	if got, want := q.String(), "SELECT 1"; got != want {
+		t.Errorf("RawSpace on empty query string: want %q, got %q", want, got)
+	}
+}
+
+func TestWhereBool(t *testing.T) {
+	tests := []struct {
+		driver string
+		v      bool
+		want   string
+	}{
+		{"pg", true, "SELECT * FROM flags WHERE enabled"},
+		{"pg", false, "SELECT * FROM flags WHERE NOT enabled"},
+		{"mysql", true, "SELECT * FROM flags WHERE enabled = 1"},
+		{"mysql", false, "SELECT * FROM flags WHERE enabled = 0"},
+	}
+	for _, tt := range tests {
+		s := NewQuery("flags").SetDriver(tt.driver).Select()
+		if tt.v {
+			s.WhereTrue("enabled")
+		} else {
+			s.WhereFalse("enabled")
+		}
+		if got := s.String(); got != tt.want {
+			t.Errorf("WhereTrue/WhereFalse %s %v string: want %q, got %q", tt.driver, tt.v, tt.want, got)
+		}
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	s := NewQuery("test").Select("a", "b").Where("id = ?", 1)
+
+	var b strings.Builder
+	n, err := s.WriteTo(&b)
+	if err != nil {
+		t.Fatalf("WriteTo: unexpected error: %v", err)
+	}
+	if b.String() != s.String() {
+		t.Errorf("WriteTo string: want %q, got %q", s.String(), b.String())
+	}
+	if n != int64(len(s.String())) {
+		t.Errorf("WriteTo length: want %d, got %d", len(s.String()), n)
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	q := NewQuery("test")
+	q.Raw("INSERT INTO test(a,b) SELECT ?,?", 1, 2)
+
+	if got, want := q.Placeholders(3), "$3,$4,$5"; got != want {
+		t.Errorf("Placeholders string: want %q, got %q", want, got)
+	}
+
+	q.SetDriver("mysql")
+
+	if got, want := q.Placeholders(3), "?,?,?"; got != want {
+		t.Errorf("Placeholders mysql string: want %q, got %q", want, got)
+	}
+	if got := q.Placeholders(0); got != "" {
+		t.Errorf("Placeholders(0) string: want empty, got %q", got)
+	}
+	if got, want := q.SetPretty(true).Placeholders(3), "?, ?, ?"; got != want {
+		t.Errorf("Placeholders pretty mysql string: want %q, got %q", want, got)
+	}
+	if got, want := q.SetDriver("pg").Placeholders(2), "$3, $4"; got != want {
+		t.Errorf("Placeholders pretty string: want %q, got %q", want, got)
+	}
+}
+
+func TestOrderByPairs(t *testing.T) {
+	q := NewQuery("test")
+	q.Select().OrderByPairs(Order{Column: "a"}, Order{Column: "b", Desc: true}, Order{Column: "c"})
+
+	gotStr := q.String()
+	wantStr := "SELECT * FROM test ORDER BY a ASC,b DESC,c ASC"
+
+	if gotStr != wantStr {
+		t.Errorf("OrderByPairs string: want %q, got %q", wantStr, gotStr)
+	}
+}
+
+func TestUpdateNull(t *testing.T) {
+	q := NewQuery("test")
+	q.Update(map[string]interface{}{
+		"t1": Null,
+		"t2": 2,
+	}).Where("id = ?", 101)
+
+	gotStr := q.String()
+	gotArgs := q.Args()
+
+	wantStr := "UPDATE test SET t1=NULL,t2=$1 WHERE id = $2"
+	wantStr2 := "UPDATE test SET t2=$1,t1=NULL WHERE id = $2"
+	wantArgs := []interface{}{2, 101}
+
+	if gotStr != wantStr && gotStr != wantStr2 {
+		t.Errorf("Update with Null string: want %q or %q, got %q", wantStr, wantStr2, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Update with Null arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Update with Null arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+}
+
+func TestWhereIn(t *testing.T) {
+	tests := []struct {
+		values interface{}
+		want   string
+	}{
+		{[]int{1, 2, 3}, "SELECT * FROM test WHERE id IN ($1,$2,$3)"},
+		{[]string{"a", "b"}, "SELECT * FROM test WHERE id IN ($1,$2)"},
+		{[]int64{1, 2}, "SELECT * FROM test WHERE id IN ($1,$2)"},
+		{[2]uint8{1, 2}, "SELECT * FROM test WHERE id IN ($1,$2)"},
+		{[]int{}, "SELECT * FROM test WHERE 1=0"},
+	}
+	q := NewQuery("test")
+	for _, tt := range tests {
+		if got := q.Select().WhereIn("id", tt.values).String(); got != tt.want {
+			t.Errorf("WhereIn(%v) string: want %q, got %q", tt.values, tt.want, got)
+		}
+		if got := q.Select().Where(In("id", tt.values)).String(); got != tt.want {
+			t.Errorf("Where In(%v) string: want %q, got %q", tt.values, tt.want, got)
+		}
+	}
+
+	ints := []int{4, 5}
+	fast := NewQuery("test").Select().WhereInInts("id", ints)
+	slow := NewQuery("test").Select().WhereIn("id", []interface{}{4, 5})
+
+	if fast.String() != slow.String() {
+		t.Errorf("WhereInInts string: want %q, got %q", slow.String(), fast.String())
+	}
+	for i, v := range fast.Args() {
+		if v != slow.Args()[i] {
+			t.Errorf("WhereInInts arguments[%d]: want %v, got %v", i, slow.Args()[i], v)
+		}
+	}
+}
+
+func TestWhereInValues(t *testing.T) {
+	q := NewQuery("test")
+
+	got := q.Select().WhereInValues("id", []int{1, 2}).String()
+	want := "SELECT * FROM test WHERE id IN ($1,$2)"
+	if got != want {
+		t.Errorf("WhereInValues below threshold string: want %q, got %q", want, got)
+	}
+
+	ids := make([]int, InValuesThreshold+1)
+	for i := range ids {
+		ids[i] = i
+	}
+	got = q.Select().WhereInValues("id", ids).String()
+	want = "SELECT * FROM test WHERE id IN (VALUES ($1),($2),"
+	if !strings.HasPrefix(got, want) || !strings.HasSuffix(got, ",($101))") {
+		t.Errorf("WhereInValues above threshold string: want %q...,($101)), got %q", want, got)
+	}
+	if len(q.Args()) != len(ids) {
+		t.Errorf("WhereInValues arguments length: want %d, got %d", len(ids), len(q.Args()))
+	}
+
+	got = NewQuery("test").SetPretty(true).Select().WhereInValues("id", ids).String()
+	if want := "SELECT * FROM test WHERE id IN (VALUES ($1), ($2), "; !strings.HasPrefix(got, want) {
+		t.Errorf("WhereInValues pretty string: want %q..., got %q", want, got)
+	}
+
+	got = NewQuery("test").SetDriver("mysql").Select().WhereInValues("id", ids).String()
+	if strings.Contains(got, "VALUES") {
+		t.Errorf("WhereInValues mysql string: want IN list, got %q", got)
+	}
+}
+
+func BenchmarkWhereIn(b *testing.B) {
+	ids := make([]int, 100)
+	for i := range ids {
+		ids[i] = i
+	}
+	q := NewQuery("test")
+
+	b.Run("Reflect", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			q.Select().Where(In("id", ids))
+		}
+	})
+	b.Run("Ints", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			q.Select().WhereInInts("id", ids)
+		}
+	})
+}
+
+func TestInsertRows(t *testing.T) {
+	fast := NewQuery("test").Insert([]string{"a", "b"}, []interface{}{1, 2}, []interface{}{3, 4})
+	slow := NewQuery("test").Insert([]string{"a", "b"}, []int{1, 2}, &[2]int{3, 4})
+
+	if fast.String() != slow.String() {
+		t.Errorf("Insert rows string: want %q, got %q", slow.String(), fast.String())
+	}
+	if len(fast.Args()) != len(slow.Args()) {
+		t.Errorf("Insert rows arguments length: want %d, got %d", len(slow.Args()), len(fast.Args()))
+	}
+	for i, v := range fast.Args() {
+		if v != slow.Args()[i] {
+			t.Errorf("Insert rows arguments[%d]: want %v, got %v", i, slow.Args()[i], v)
+		}
+	}
+}
+
+func BenchmarkInsertRows(b *testing.B) {
+	columns := []string{"a", "b", "c"}
+	rows := make([]interface{}, 100)
+	intRows := make([]interface{}, 100)
+	for i := range rows {
+		rows[i] = []interface{}{i, i, i}
+		intRows[i] = []int{i, i, i}
+	}
+	q := NewQuery("test")
+
+	b.Run("Interface", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			q.Insert(columns, rows...)
+		}
+	})
+	b.Run("Reflect", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			q.Insert(columns, intRows...)
+		}
+	})
+}
+
+func TestNewQueryCap(t *testing.T) {
+	q := NewQueryCap(4, 64, "test")
+	q.Insert([]string{"a", "b"}, []interface{}{1, 2}, []interface{}{3, 4})
+
+	gotStr := q.String()
+	wantStr := "INSERT INTO test(a,b) VALUES ($1,$2),($3,$4)"
+
+	if gotStr != wantStr {
+		t.Errorf("NewQueryCap Insert string: want %q, got %q", wantStr, gotStr)
+	}
+	if got := cap(q.Args()); got != 4 {
+		t.Errorf("NewQueryCap arguments capacity: want 4, got %d", got)
+	}
+	if got := q.Select().Args(); got != nil {
+		t.Errorf("NewQueryCap Select arguments: want <nil>, got %v", got)
+	}
+}
+
+func BenchmarkNewQueryCap(b *testing.B) {
+	columns := []string{"a", "b", "c", "d"}
+	rows := make([]interface{}, 1000)
+	for i := range rows {
+		rows[i] = []interface{}{i, i, i, i}
+	}
+
+	b.Run("NewQuery", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewQuery("test").Insert(columns, rows...)
+		}
+	})
+	b.Run("NewQueryCap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewQueryCap(4000, 32000, "test").Insert(columns, rows...)
+		}
+	})
+}
+
+func TestAppendQuery(t *testing.T) {
+	s := NewQuery("test").Select().Where("id = ?", 1)
+	buf := []byte("EXPLAIN ")
+
+	got := string(s.AppendQuery(buf))
+	want := "EXPLAIN SELECT * FROM test WHERE id = $1"
+
+	if got != want {
+		t.Errorf("AppendQuery string: want %q, got %q", want, got)
+	}
+}
+
+func BenchmarkAddArg(b *testing.B) {
+	columns := make([]string, 1000)
+	values := make([]interface{}, 1000)
+	for i := range columns {
+		columns[i] = "c"
+		values[i] = i
+	}
+
+	// lookup is the placeholder writer resolving the dialect for every argument,
+	// as addArg did before the writer was cached by SetDriver.
+	lookup := func(q *Query) {
+		if p := q.dialect().prefix; p != "" {
+			q.str.WriteString(p)
+			q.str.WriteString(strconv.Itoa(len(q.args)))
+		} else {
+			q.str.WriteByte('?')
+		}
+	}
+	writers := []struct {
+		name string
+		fn   func(q *Query)
+	}{
+		{"cached", nil},
+		{"lookup", lookup},
+	}
+
+	for _, driver := range []string{"pg", "mysql"} {
+		for _, w := range writers {
+			q := NewQuery("test").SetDriver(driver)
+			if w.fn != nil {
+				q.placeholder = w.fn
+			}
+			b.Run(driver+"/"+w.name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					q.Insert(columns, values...)
+				}
+			})
+		}
+	}
+}
+
+func TestTrim(t *testing.T) {
+	s := NewQuery("test").Select().Where("id = ?", 1).Raw("; \n")
+
+	gotStr := s.Trim().String()
+	wantStr := "SELECT * FROM test WHERE id = $1"
+
+	if gotStr != wantStr {
+		t.Errorf("Trim string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(s.Args()) != 1 {
+		t.Errorf("Trim arguments length: want 1, got %d", len(s.Args()))
+	}
+}
+
+func TestDistinctIf(t *testing.T) {
+	q := NewQuery("orders")
+
+	if got, want := q.DistinctIf(false).Select("customer_id").String(), "SELECT customer_id FROM orders"; got != want {
+		t.Errorf("DistinctIf(false) string: want %q, got %q", want, got)
+	}
+	if got, want := q.DistinctIf(true).Select("customer_id").String(), "SELECT DISTINCT customer_id FROM orders"; got != want {
+		t.Errorf("DistinctIf(true) string: want %q, got %q", want, got)
+	}
+}
+
+func TestUpdateDefault(t *testing.T) {
+	q := NewQuery("test")
+	q.Update("t1 = ?, t2 = ?", Default, 2).Where("id = ?", 101)
+
+	gotStr := q.String()
+	wantStr := "UPDATE test SET t1 = DEFAULT, t2 = $1 WHERE id = $2"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{2, 101}
+
+	if gotStr != wantStr {
+		t.Errorf("Update with Default string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Update with Default arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Update with Default arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q.Update(map[string]interface{}{"t1": Default})
+	if got, want := q.String(), "UPDATE test SET t1=DEFAULT"; got != want {
+		t.Errorf("Update map with Default string: want %q, got %q", want, got)
+	}
+}
+
+func TestOrderByUserInput(t *testing.T) {
+	q := NewQuery("users").SetOrderByColumns("name", "created_at")
+
+	s := q.Select("id").OrderByUserInput("created_at", "desc")
+	if got, want := s.String(), "SELECT id FROM users ORDER BY created_at DESC"; got != want {
+		t.Errorf("OrderByUserInput string: want %q, got %q", want, got)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("OrderByUserInput: unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		column, dir string
+	}{
+		{"password", "ASC"},
+		{"name; DROP TABLE users", "ASC"},
+		{"name", "ASC; DROP TABLE users"},
+		{"name", ""},
+	}
+	for _, tt := range tests {
+		s := q.Select("id").OrderByUserInput(tt.column, tt.dir)
+		if s.Err() == nil {
+			t.Errorf("OrderByUserInput(%q, %q): want error", tt.column, tt.dir)
+		}
+		if got, want := s.String(), "SELECT id FROM users"; got != want {
+			t.Errorf("OrderByUserInput(%q, %q) string: want %q, got %q", tt.column, tt.dir, want, got)
+		}
+	}
+}
+
+func TestStraightJoin(t *testing.T) {
+	q := NewQuery("users u").SetDriver("mysql")
+	q.Select("u.name").StraightJoin("orders o", "o.user_id = u.id AND o.total > ?", 10)
+
+	gotStr := q.String()
+	wantStr := "SELECT u.name FROM users u STRAIGHT_JOIN orders o ON o.user_id = u.id AND o.total > ?"
+
+	if gotStr != wantStr {
+		t.Errorf("StraightJoin string: want %q, got %q", wantStr, gotStr)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("StraightJoin with pg driver: want panic")
+		}
+	}()
+	NewQuery("users u").Select().StraightJoin("orders o", "o.user_id = u.id")
+}
+
+func TestEmbed(t *testing.T) {
+	tests := []struct {
+		driver string
+		offset int
+		want   string
+	}{
+		{"pg", 0, "a = $1 AND b = '$1' AND c IN ($2,$3)"},
+		{"pg", 2, "a = $3 AND b = '$1' AND c IN ($4,$5)"},
+		{"pg", 11, "a = $12 AND b = '$1' AND c IN ($13,$14)"},
+		{"mssql", 2, "a = @p3 AND b = '$1' AND c IN (@p4,@p5)"},
+		{"mysql", 2, "a = ? AND b = '$1' AND c IN (?,?)"},
+		{"sqlite", 0, "a = ? AND b = '$1' AND c IN (?,?)"},
+	}
+	for _, tt := range tests {
+		sub := NewQuery().SetDriver(tt.driver)
+		sub.Raw("a = ? AND b = '$1' AND c IN (?,?)", 1, 2, 3)
+
+		q := NewQuery().SetDriver(tt.driver)
+		for i := 0; i < tt.offset; i++ {
+			q.appendArgs(0)
+		}
+		q.embed(sub)
+
+		if got := q.String(); got != tt.want {
+			t.Errorf("embed %s at offset %d string: want %q, got %q", tt.driver, tt.offset, tt.want, got)
+		}
+		args := q.Args()
+		if len(args) != tt.offset+3 {
+			t.Errorf("embed %s at offset %d arguments length: want %d, got %d", tt.driver, tt.offset, tt.offset+3, len(args))
+			continue
+		}
+		for i, v := range args[tt.offset:] {
+			if v != i+1 {
+				t.Errorf("embed %s at offset %d arguments[%d]: want %v, got %v", tt.driver, tt.offset, tt.offset+i, i+1, v)
+			}
+		}
+		if got := sub.String(); got != NewQuery().SetDriver(tt.driver).Raw("a = ? AND b = '$1' AND c IN (?,?)", 1, 2, 3).String() {
+			t.Errorf("embed %s at offset %d changed sub query string: %q", tt.driver, tt.offset, got)
+		}
+	}
+}
+
+func TestWhereNotIn(t *testing.T) {
+	q := NewQuery("test")
+
+	if got, want := q.Select().WhereNotIn("id", []int{1, 2}).String(), "SELECT * FROM test WHERE id NOT IN ($1,$2)"; got != want {
+		t.Errorf("WhereNotIn string: want %q, got %q", want, got)
+	}
+	if args := q.Args(); len(args) != 2 || args[0] != 1 || args[1] != 2 {
+		t.Errorf("WhereNotIn arguments: want [1 2], got %v", args)
+	}
+	if got, want := q.Select().WhereNotIn("id", []int{}).String(), "SELECT * FROM test WHERE 1=1"; got != want {
+		t.Errorf("WhereNotIn empty string: want %q, got %q", want, got)
+	}
+}
+
+func TestLimitSub(t *testing.T) {
+	sub := NewQuery("config").Select("max_rows").Where("name = ?", "feed")
+	q := NewQuery("posts")
+	q.Select("id").Where("user_id = ?", 7).LimitSub(sub)
+
+	gotStr := q.String()
+	wantStr := "SELECT id FROM posts WHERE user_id = $1 LIMIT (SELECT max_rows FROM config WHERE name = $2)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{7, "feed"}
+
+	if gotStr != wantStr {
+		t.Errorf("LimitSub string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("LimitSub arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("LimitSub arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("LimitSub with mysql driver: want panic")
+		}
+	}()
+	NewQuery("posts").SetDriver("mysql").Select().LimitSub(sub)
+}
+
+func TestWhereBetweenCols(t *testing.T) {
+	q := NewQuery("tiers")
+	q.Select("name").WhereBetweenCols(250, "min_points", "max_points")
+
+	gotStr := q.String()
+	wantStr := "SELECT name FROM tiers WHERE $1 BETWEEN min_points AND max_points"
+
+	if gotStr != wantStr {
+		t.Errorf("WhereBetweenCols string: want %q, got %q", wantStr, gotStr)
+	}
+	if args := q.Args(); len(args) != 1 || args[0] != 250 {
+		t.Errorf("WhereBetweenCols arguments: want [250], got %v", args)
+	}
+}
+
+func TestTableSample(t *testing.T) {
+	q := NewQuery("events e")
+	q.TableSample("system", 10).Select("COUNT(*)").Join("users u", "u.id = e.user_id").Where("e.kind = ?", "click")
+
+	gotStr := q.String()
+	wantStr := "SELECT COUNT(*) FROM events e TABLESAMPLE SYSTEM (10) JOIN users u ON u.id = e.user_id WHERE e.kind = $1"
+
+	if gotStr != wantStr {
+		t.Errorf("TableSample string: want %q, got %q", wantStr, gotStr)
+	}
+	if got, want := q.Select().String(), "SELECT * FROM events e"; got != want {
+		t.Errorf("Select after TableSample string: want %q, got %q", want, got)
+	}
+	if got, want := q.TableSample("BERNOULLI", 0.5).Select().String(), "SELECT * FROM events e TABLESAMPLE BERNOULLI (0.5)"; got != want {
+		t.Errorf("TableSample bernoulli string: want %q, got %q", want, got)
+	}
+
+	tests := []struct {
+		driver, method string
+		percentage     float64
+	}{
+		{"mysql", "SYSTEM", 10},
+		{"pg", "SYSTEM_ROWS", 10},
+		{"pg", "SYSTEM", 101},
+		{"pg", "SYSTEM", -1},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("TableSample(%q, %v) with %s driver: want panic", tt.method, tt.percentage, tt.driver)
+				}
+			}()
+			NewQuery("events").SetDriver(tt.driver).TableSample(tt.method, tt.percentage)
+		}()
+	}
+}
+
+func TestWith(t *testing.T) {
+	ins := NewQuery("users").Insert([]string{"email"}, "a@b.c").Returning("id")
+	upd := NewQuery("stats").Update("signups = signups + ?", 1).Where("day = ?", "2020-01-01").Returning("signups")
+
+	q := NewQuery("ins, upd")
+	q.With("ins", ins).With("upd", upd).Select("ins.id", "upd.signups").Where("ins.id > ?", 0)
+
+	gotStr := q.String()
+	wantStr := "WITH ins AS (INSERT INTO users(email) VALUES ($1) RETURNING id),upd AS (UPDATE stats SET signups = signups + $2 WHERE day = $3 RETURNING signups) SELECT ins.id,upd.signups FROM ins, upd WHERE ins.id > $4"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{"a@b.c", 1, "2020-01-01", 0}
+
+	if gotStr != wantStr {
+		t.Errorf("With string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("With arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("With arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	if got, want := q.Select().String(), "SELECT * FROM ins, upd"; got != want {
+		t.Errorf("Select after With string: want %q, got %q", want, got)
+	}
+
+	sel := NewQuery("users").SetDriver("mysql").Select("id").Where("active = ?", true)
+	if got, want := NewQuery("a").SetDriver("mysql").With("a", sel).Select().String(), "WITH a AS (SELECT id FROM users WHERE active = ?) SELECT * FROM a"; got != want {
+		t.Errorf("With mysql string: want %q, got %q", want, got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("With delete statement with mysql driver: want panic")
+		}
+	}()
+	NewQuery("d").SetDriver("mysql").With("d", NewQuery("users").SetDriver("mysql").Delete())
+}
+
+func TestArg(t *testing.T) {
+	q := NewQuery("test")
+	q.Select().Where("a = ? AND b = ? AND c = ?", 1, "two", 3.0)
+
+	if got := q.Arg(2); got != "two" {
+		t.Errorf("Arg(2): want %v, got %v", "two", got)
+	}
+	if got := q.Arg(3); got != 3.0 {
+		t.Errorf("Arg(3): want %v, got %v", 3.0, got)
+	}
+
+	for _, i := range []int{0, 4} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Arg(%d): want panic", i)
+				}
+			}()
+			q.Arg(i)
+		}()
+	}
+}
+
+func TestInsertPartial(t *testing.T) {
+	q := NewQuery("users")
+	q.InsertPartial(map[string]interface{}{"name": "n1", "email": "a@b.c"})
+
+	gotStr := q.String()
+	wantStr := "INSERT INTO users(email,name) VALUES ($1,$2)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{"a@b.c", "n1"}
+
+	if gotStr != wantStr {
+		t.Errorf("InsertPartial string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("InsertPartial arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("InsertPartial arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	if got, want := q.InsertPartial(nil).String(), "INSERT INTO users DEFAULT VALUES"; got != want {
+		t.Errorf("InsertPartial empty string: want %q, got %q", want, got)
+	}
+	if got, want := q.SetDriver("mysql").InsertPartial(nil).String(), "INSERT INTO users () VALUES ()"; got != want {
+		t.Errorf("InsertPartial empty mysql string: want %q, got %q", want, got)
+	}
+
+	q = NewQuery("posts")
+	q.InsertPartial(map[string]interface{}{"a_tags": []int{1, 2}, "b": 3})
+	if got, want := q.String(), "INSERT INTO posts(a_tags,b) VALUES ($1,$2)"; got != want {
+		t.Errorf("InsertPartial slice value string: want %q, got %q", want, got)
+	}
+	if args := q.Args(); len(args) != 2 || args[1] != 3 {
+		t.Errorf("InsertPartial slice value arguments: want [[1 2] 3], got %v", args)
+	}
+}
+
+func TestHasClauses(t *testing.T) {
+	q := NewQuery("users u")
+	s := q.Select()
+
+	if s.HasWhere() || s.HasOrderBy() || s.HasLimit() || s.HasJoin() {
+		t.Errorf("Select clauses: want none")
+	}
+	if !s.Join("orders o", "o.user_id = u.id").HasJoin() {
+		t.Errorf("HasJoin after Join: want true")
+	}
+	if !s.Where("u.id = ?", 1).HasWhere() {
+		t.Errorf("HasWhere after Where: want true")
+	}
+	if !s.OrderBy("u.id").HasOrderBy() {
+		t.Errorf("HasOrderBy after OrderBy: want true")
+	}
+	if !s.Limit(10).HasLimit() {
+		t.Errorf("HasLimit after Limit: want true")
+	}
+
+	s = q.Select()
+	if s.HasWhere() || s.HasOrderBy() || s.HasLimit() || s.HasJoin() {
+		t.Errorf("Select clauses after new statement: want none")
+	}
+}
+
+func TestSetDefaultOrderBy(t *testing.T) {
+	q := NewQuery("posts").SetDefaultOrderBy("id")
+
+	if got, want := q.Select().Limit(10).Offset(20).String(), "SELECT * FROM posts ORDER BY id LIMIT $1 OFFSET $2"; got != want {
+		t.Errorf("Limit with default order by string: want %q, got %q", want, got)
+	}
+	if got, want := q.Select().OrderByDesc("created_at").Limit(10).String(), "SELECT * FROM posts ORDER BY created_at DESC LIMIT $1"; got != want {
+		t.Errorf("Limit with order by string: want %q, got %q", want, got)
+	}
+	if got, want := q.Select().Where("id = ?", 1).String(), "SELECT * FROM posts WHERE id = $1"; got != want {
+		t.Errorf("Select without limit string: want %q, got %q", want, got)
+	}
+}
+
+func TestEmptyQuery(t *testing.T) {
+	q := NewQuery()
+
+	if got := q.String(); got != "" {
+		t.Errorf("Unbuilt query string: want empty, got %q", got)
+	}
+	if got := q.Table(); got != "" {
+		t.Errorf("Table without tables: want empty, got %q", got)
+	}
+	if err := q.Select().Where("id = ?", 1).Err(); err == nil {
+		t.Errorf("Select without tables: want error")
+	}
+	if err := q.SetTable("users").Select().Err(); err != nil {
+		t.Errorf("Select after SetTable: unexpected error: %v", err)
+	}
+}
+
+func TestCrossApply(t *testing.T) {
+	sub := NewQuery("orders o").SetDriver("mssql").Select("TOP 3 o.id", "o.total").Where("o.user_id = u.id AND o.total > ?", 10).OrderByDesc("o.total")
+	q := NewQuery("users u").SetDriver("mssql")
+	q.Select("u.name", "t.total").CrossApply(sub, "t").Where("u.active = ?", true)
+
+	gotStr := q.String()
+	wantStr := "SELECT u.name,t.total FROM users u CROSS APPLY (SELECT TOP 3 o.id,o.total FROM orders o WHERE o.user_id = u.id AND o.total > @p1 ORDER BY o.total DESC) t WHERE u.active = @p2"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{10, true}
+
+	if gotStr != wantStr {
+		t.Errorf("CrossApply string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("CrossApply arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("CrossApply arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q.Select("u.name").Join("teams m", "m.id = u.team_id AND m.kind = ?", "pro").OuterApply(sub, "t")
+
+	gotStr = q.String()
+	wantStr = "SELECT u.name FROM users u JOIN teams m ON m.id = u.team_id AND m.kind = @p1 OUTER APPLY (SELECT TOP 3 o.id,o.total FROM orders o WHERE o.user_id = u.id AND o.total > @p2 ORDER BY o.total DESC) t"
+
+	if gotStr != wantStr {
+		t.Errorf("OuterApply string: want %q, got %q", wantStr, gotStr)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("CrossApply with pg driver: want panic")
+		}
+	}()
+	NewQuery("users").Select().CrossApply(sub, "t")
+}
+
+func TestHint(t *testing.T) {
+	q := NewQuery("users").SetDriver("mysql")
+	q.Hint("MAX_EXECUTION_TIME(1000)").Distinct().Select("id").Where("active = ?", true)
+
+	gotStr := q.String()
+	wantStr := "SELECT /*+ MAX_EXECUTION_TIME(1000) */ DISTINCT id FROM users WHERE active = ?"
+
+	if gotStr != wantStr {
+		t.Errorf("Hint string: want %q, got %q", wantStr, gotStr)
+	}
+	if got, want := q.Hint("NO_ICP(users) */ DROP TABLE users; /*").Select("id").String(), "SELECT /*+ NO_ICP(users)  DROP TABLE users; */ id FROM users"; got != want {
+		t.Errorf("Hint sanitized string: want %q, got %q", want, got)
+	}
+	if got, want := q.Hint("a **// b").Select("id").String(), "SELECT /*+ a  b */ id FROM users"; got != want {
+		t.Errorf("Hint nested delimiters string: want %q, got %q", want, got)
+	}
+	if got, want := q.Select("id").String(), "SELECT id FROM users"; got != want {
+		t.Errorf("Select after Hint string: want %q, got %q", want, got)
+	}
+	if got, want := NewQuery("users").Hint("SeqScan(users)").Select("id").String(), "SELECT id FROM users"; got != want {
+		t.Errorf("Hint pg string: want %q, got %q", want, got)
+	}
+}
+
+func TestPendingModifiersLeak(t *testing.T) {
+	tests := []struct {
+		name  string
+		begin func(q *Query)
+	}{
+		{"SelectExists", func(q *Query) { q.SelectExists(NewQuery("orders").Select("1")) }},
+		{"Delete", func(q *Query) { q.Delete() }},
+		{"Insert", func(q *Query) { q.Insert([]string{"id"}, 1) }},
+	}
+	for _, tt := range tests {
+		q := NewQuery("users").SetDriver("mysql")
+		q.Hint("NO_ICP(users)").Distinct()
+		tt.begin(q)
+		if got, want := q.Select("id").String(), "SELECT id FROM users"; got != want {
+			t.Errorf("Select after %s string: want %q, got %q", tt.name, want, got)
+		}
+
+		q = NewQuery("users")
+		q.TableSample("SYSTEM", 10).DistinctOn("email")
+		tt.begin(q)
+		if got, want := q.Select("id").String(), "SELECT id FROM users"; got != want {
+			t.Errorf("Select after %s pg string: want %q, got %q", tt.name, want, got)
+		}
+	}
+}
+
+func TestScalarSub(t *testing.T) {
+	avg := NewQuery("orders").Select("AVG(total)").Where("shop_id = ?", 3)
+	q := NewQuery("orders")
+	q.Select("id").Where("shop_id = ?", 3).Raw(" AND total > ").ScalarSub(avg)
+
+	gotStr := q.String()
+	wantStr := "SELECT id FROM orders WHERE shop_id = $1 AND total > (SELECT AVG(total) FROM orders WHERE shop_id = $2)"
+
+	if gotStr != wantStr {
+		t.Errorf("ScalarSub string: want %q, got %q", wantStr, gotStr)
+	}
+	if err := q.Err(); err != nil {
+		t.Errorf("ScalarSub: unexpected error: %v", err)
+	}
+
+	multi := NewQuery("orders").Select("id", "total")
+	if err := q.Select("id").Raw(" WHERE total > ").ScalarSub(multi).Err(); err == nil {
+		t.Errorf("ScalarSub with multiple columns: want error")
+	}
+	if err := q.Select("id").WhereOpAny("id", "=", multi).Err(); err == nil {
+		t.Errorf("WhereOpAny with multiple columns: want error")
+	}
+	if err := q.Select("id").LimitSub(multi).Err(); err == nil {
+		t.Errorf("LimitSub with multiple columns: want error")
+	}
+}
+
+func TestRawNamed(t *testing.T) {
+	q := NewQuery("users")
+	q.Select("id").RawNamed(" WHERE tenant_id = :tenant AND status = ? AND created_at::date > ? AND note <> ':x' AND (owner_id = :tenant OR age > ?)", map[string]interface{}{"tenant": 7}, "active", "2020-01-01", 18)
+
+	gotStr := q.String()
+	wantStr := "SELECT id FROM users WHERE tenant_id = $1 AND status = $2 AND created_at::date > $3 AND note <> ':x' AND (owner_id = $4 OR age > $5)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{7, "active", "2020-01-01", 7, 18}
+
+	if gotStr != wantStr {
+		t.Errorf("RawNamed string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("RawNamed arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("RawNamed arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	if err := q.Select("id").RawNamed(" WHERE a = :missing", nil).Err(); err == nil {
+		t.Errorf("RawNamed with missing name: want error")
+	}
+	if err := q.Select("id").RawNamed(" WHERE a = ? AND b = ?", nil, 1).Err(); err == nil {
+		t.Errorf("RawNamed with missing argument: want error")
+	}
+	if err := q.Select("id").RawNamed(" WHERE a = ?", nil, 1, 2).Err(); err == nil {
+		t.Errorf("RawNamed with unused argument: want error")
+	}
+
+	m := NewQuery("users").SetDriver("mysql")
+	m.Select("id").RawNamed(" WHERE `a:x?` = :b", map[string]interface{}{"b": 1})
+	if got, want := m.String(), "SELECT id FROM users WHERE `a:x?` = ?"; got != want {
+		t.Errorf("RawNamed backtick identifier string: want %q, got %q", want, got)
+	}
+	if err := m.Err(); err != nil {
+		t.Errorf("RawNamed backtick identifier: unexpected error: %v", err)
+	}
+}
+
+func TestSetTerminate(t *testing.T) {
+	q := NewQuery("test")
+	s := q.Select().Where("id = ?", 1)
+
+	if got, want := s.String(), "SELECT * FROM test WHERE id = $1"; got != want {
+		t.Errorf("Non terminated string: want %q, got %q", want, got)
+	}
+
+	q.SetTerminate(true)
+	want := "SELECT * FROM test WHERE id = $1;"
+	if got := s.String(); got != want {
+		t.Errorf("Terminated string: want %q, got %q", want, got)
+	}
+	if got := string(s.AppendQuery(nil)); got != want {
+		t.Errorf("Terminated AppendQuery: want %q, got %q", want, got)
+	}
+	if got := q.Select().Raw(";").String(); got != "SELECT * FROM test;" {
+		t.Errorf("Terminated string ending with semicolon: want %q, got %q", "SELECT * FROM test;", got)
+	}
+	if got := NewQuery("test").SetTerminate(true).String(); got != "" {
+		t.Errorf("Terminated unbuilt query string: want empty, got %q", got)
+	}
+}
+
+func TestWhereJSONEq(t *testing.T) {
+	tests := []struct {
+		driver, want string
+	}{
+		{"pg", "SELECT id FROM users WHERE profile #>> '{address,city}' = $1"},
+		{"mysql", "SELECT id FROM users WHERE profile->>'$.address.city' = ?"},
+		{"sqlite", "SELECT id FROM users WHERE json_extract(profile, '$.address.city') = ?"},
+		{"mssql", "SELECT id FROM users WHERE JSON_VALUE(profile, '$.address.city') = @p1"},
+	}
+	for _, tt := range tests {
+		q := NewQuery("users").SetDriver(tt.driver)
+		q.Select("id").WhereJSONEq("profile", "address.city", "Paris")
+
+		if got := q.String(); got != tt.want {
+			t.Errorf("WhereJSONEq %s string: want %q, got %q", tt.driver, tt.want, got)
+		}
+		if args := q.Args(); len(args) != 1 || args[0] != "Paris" {
+			t.Errorf("WhereJSONEq %s arguments: want [Paris], got %v", tt.driver, args)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("WhereJSONEq with invalid path: want panic")
+		}
+	}()
+	NewQuery("users").SetDriver("mysql").Select("id").WhereJSONEq("profile", `a\' OR 1=1`, 1)
+}
+
+func TestClearWhere(t *testing.T) {
+	q := NewQuery("users u")
+	s := q.Select("u.id").Join("teams t", "t.id = u.team_id AND t.kind = ?", "pro").Where("u.id = ?", 1).And("u.active = ?", true)
+	s.ClearWhere().Where("u.email = ?", "a@b.c")
+
+	gotStr := s.String()
+	wantStr := "SELECT u.id FROM users u JOIN teams t ON t.id = u.team_id AND t.kind = $1 WHERE u.email = $2"
+	gotArgs := s.Args()
+	wantArgs := []interface{}{"pro", "a@b.c"}
+
+	if gotStr != wantStr {
+		t.Errorf("ClearWhere string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("ClearWhere arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("ClearWhere arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+	if s.ClearWhere().HasWhere() {
+		t.Errorf("HasWhere after ClearWhere: want false")
+	}
+
+	tests := []struct {
+		name   string
+		clause func(s *Statement)
+	}{
+		{"order by", func(s *Statement) { s.OrderBy("id") }},
+		{"group by", func(s *Statement) { s.GroupBy("id") }},
+		{"having", func(s *Statement) { s.GroupBy("id").Having("COUNT(*) > ?", 1) }},
+		{"offset", func(s *Statement) { s.Limit(10).Offset(20) }},
+		{"returning", func(s *Statement) { s.Returning("id") }},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("ClearWhere followed by %s: want panic", tt.name)
+				}
+			}()
+			s := q.Select().Where("id = ?", 1)
+			if tt.name == "returning" {
+				s = q.Delete().Where("id = ?", 1)
+			}
+			tt.clause(s)
+			s.ClearWhere()
+		}()
+	}
+}
+
+func TestRow(t *testing.T) {
+	q := NewQuery("orders")
+	q.Select("id").Where("status = ?", "open").And("(tenant_id,order_no) = ?", Row(7, "A-1"))
+
+	gotStr := q.String()
+	wantStr := "SELECT id FROM orders WHERE status = $1 AND (tenant_id,order_no) = ($2,$3)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{"open", 7, "A-1"}
+
+	if gotStr != wantStr {
+		t.Errorf("Row string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("Row arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("Row arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q.SetDriver("mysql").Select("id").Where("(a,b) IN (?,?)", Row(1, 2), Row(3, 4))
+	if got, want := q.String(), "SELECT id FROM orders WHERE (a,b) IN ((?,?),(?,?))"; got != want {
+		t.Errorf("Row mysql string: want %q, got %q", want, got)
+	}
+	if got := len(q.Args()); got != 4 {
+		t.Errorf("Row mysql arguments length: want 4, got %d", got)
+	}
+}
+
+func TestWhereDateRange(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     string
+		args     []interface{}
+	}{
+		{"full", from, to, "SELECT id FROM orders WHERE created_at >= $1 AND created_at < $2", []interface{}{from, to}},
+		{"from", from, time.Time{}, "SELECT id FROM orders WHERE created_at >= $1", []interface{}{from}},
+		{"to", time.Time{}, to, "SELECT id FROM orders WHERE created_at < $1", []interface{}{to}},
+		{"none", time.Time{}, time.Time{}, "SELECT id FROM orders", nil},
+	}
+	q := NewQuery("orders")
+	for _, tt := range tests {
+		q.Select("id").WhereDateRange("created_at", tt.from, tt.to)
+		if got := q.String(); got != tt.want {
+			t.Errorf("WhereDateRange %s string: want %q, got %q", tt.name, tt.want, got)
+		}
+		gotArgs := q.Args()
+		if len(gotArgs) != len(tt.args) {
+			t.Errorf("WhereDateRange %s arguments length: want %d, got %d", tt.name, len(tt.args), len(gotArgs))
+			continue
+		}
+		for i, v := range gotArgs {
+			if v != tt.args[i] {
+				t.Errorf("WhereDateRange %s arguments[%d]: want %v, got %v", tt.name, i, tt.args[i], v)
+			}
+		}
+	}
+}
+
+func TestWhereExpr(t *testing.T) {
+	q := NewQuery("users")
+	q.Select("id").WhereExpr("LOWER(email)", "=", "a@b.c").And("active = ?", true)
+
+	gotStr := q.String()
+	wantStr := "SELECT id FROM users WHERE LOWER(email) = $1 AND active = $2"
+
+	if gotStr != wantStr {
+		t.Errorf("WhereExpr string: want %q, got %q", wantStr, gotStr)
+	}
+	if args := q.Args(); len(args) != 2 || args[0] != "a@b.c" {
+		t.Errorf("WhereExpr arguments: want [a@b.c true], got %v", args)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("WhereExpr invalid operator: want panic")
+		}
+	}()
+	q.Select("id").WhereExpr("LOWER(email)", "= 1 OR", "x")
+}
+
+func TestSetMaxRows(t *testing.T) {
+	q := NewQuery("events").SetMaxRows(100)
+
+	q.Select("id").Where("kind = ?", "click")
+	if got, want := q.String(), "SELECT id FROM events WHERE kind = $1 LIMIT 100"; got != want {
+		t.Errorf("SetMaxRows no limit string: want %q, got %q", want, got)
+	}
+	if got := len(q.Args()); got != 1 {
+		t.Errorf("SetMaxRows no limit arguments length: want 1, got %d", got)
+	}
+
+	q.Select("id").Limit(10)
+	if got, want := q.String(), "SELECT id FROM events LIMIT $1"; got != want {
+		t.Errorf("SetMaxRows smaller limit string: want %q, got %q", want, got)
+	}
+	if args := q.Args(); len(args) != 1 || args[0] != 10 {
+		t.Errorf("SetMaxRows smaller limit arguments: want [10], got %v", args)
+	}
+
+	q.Select("id").Limit(500)
+	if args := q.Args(); len(args) != 1 || args[0] != 100 {
+		t.Errorf("SetMaxRows larger limit arguments: want [100], got %v", args)
+	}
+
+	q.Select("id").ForUpdate()
+	if got, want := q.String(), "SELECT id FROM events LIMIT 100 FOR UPDATE"; got != want {
+		t.Errorf("SetMaxRows lock string: want %q, got %q", want, got)
+	}
+
+	q.Delete()
+	if got, want := q.String(), "DELETE FROM events"; got != want {
+		t.Errorf("SetMaxRows delete string: want %q, got %q", want, got)
+	}
+
+	tests := []struct {
+		name   string
+		driver string
+		build  func(q *Query) *Statement
+		want   string
+	}{
+		{"offset", "mysql", func(q *Query) *Statement { return q.Select("id").OrderBy("id").Offset(20) }, "SELECT id FROM events ORDER BY id LIMIT 100 OFFSET ?"},
+		{"offset", "sqlite", func(q *Query) *Statement { return q.Select("id").OrderBy("id").Offset(20) }, "SELECT id FROM events ORDER BY id LIMIT 100 OFFSET ?"},
+		{"offset", "pg", func(q *Query) *Statement { return q.Select("id").OrderBy("id").Offset(20) }, "SELECT id FROM events ORDER BY id OFFSET $1 LIMIT 100"},
+		{"no limit", "mssql", func(q *Query) *Statement { return q.Select("id") }, "SELECT id FROM events ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 100 ROWS ONLY"},
+		{"offset", "mssql", func(q *Query) *Statement { return q.Select("id").OrderBy("id").Offset(20) }, "SELECT id FROM events ORDER BY id OFFSET @p1 ROWS FETCH NEXT 100 ROWS ONLY"},
+		{"sub query", "pg", func(q *Query) *Statement {
+			return NewQuery("users").SelectExists(q.Select("user_id"))
+		}, "SELECT EXISTS(SELECT user_id FROM events LIMIT 100)"},
+		{"union", "sqlite", func(q *Query) *Statement {
+			return q.Select("id").Union(NewQuery("events").SetDriver("sqlite").SetMaxRows(100).Select("id"))
+		}, "SELECT id FROM events UNION SELECT id FROM events LIMIT 100"},
+	}
+	for _, tt := range tests {
+		if got := tt.build(NewQuery("events").SetDriver(tt.driver).SetMaxRows(100)).String(); got != tt.want {
+			t.Errorf("SetMaxRows %s %s string: want %q, got %q", tt.name, tt.driver, tt.want, got)
+		}
+	}
+
+	b := NewBatch(NewQuery("events").SetMaxRows(100).Select("id"), NewQuery("events").Select("id").Where("id = ?", 1))
+	if got, want := b.String(), "SELECT id FROM events LIMIT 100; SELECT id FROM events WHERE id = $1"; got != want {
+		t.Errorf("SetMaxRows batch string: want %q, got %q", want, got)
+	}
+
+	s := NewQuery("events").SetMaxRows(100).Select("id").OrderBy("id").WithHasMore(100)
+	if args := s.Args(); len(args) != 1 || args[0] != 101 {
+		t.Errorf("SetMaxRows WithHasMore arguments: want [101], got %v", args)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("SetMaxRows WithHasMore: unexpected error: %v", err)
+	}
+	if err := NewQuery("events").SetMaxRows(100).Select("id").WithHasMore(200).Err(); err == nil {
+		t.Errorf("SetMaxRows WithHasMore exceeding max rows: want error")
+	}
+}
+
+func TestGroupByRollup(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{"pg", "SELECT region,city,SUM(total) FROM sales GROUP BY ROLLUP(region,city)"},
+		{"mssql", "SELECT region,city,SUM(total) FROM sales GROUP BY ROLLUP(region,city)"},
+		{"mysql", "SELECT region,city,SUM(total) FROM sales GROUP BY region,city WITH ROLLUP"},
+	}
+	for _, tt := range tests {
+		q := NewQuery("sales").SetDriver(tt.driver)
+		q.Select("region", "city", "SUM(total)").GroupByRollup("region", "city")
+		if got := q.String(); got != tt.want {
+			t.Errorf("GroupByRollup %s string: want %q, got %q", tt.driver, tt.want, got)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("GroupByRollup sqlite: want panic")
+		}
+	}()
+	NewQuery("sales").SetDriver("sqlite").Select("region").GroupByRollup("region")
+}
+
+func TestInsertIfNotExists(t *testing.T) {
+	q := NewQuery("users")
+	q.InsertIfNotExists([]string{"email", "name"}, []interface{}{"a@b.c", "Bob"}, "email = ?", "a@b.c").Returning("id")
+
+	gotStr := q.String()
+	wantStr := "INSERT INTO users(email,name) SELECT $1,$2 WHERE NOT EXISTS (SELECT 1 FROM users WHERE email = $3) RETURNING id"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{"a@b.c", "Bob", "a@b.c"}
+
+	if gotStr != wantStr {
+		t.Errorf("InsertIfNotExists string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("InsertIfNotExists arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("InsertIfNotExists arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+
+	q.SetDriver("mysql").InsertIfNotExists([]string{"email"}, []interface{}{"a@b.c"}, "email = ?", "a@b.c")
+	if got, want := q.String(), "INSERT INTO users(email) SELECT ? FROM DUAL WHERE NOT EXISTS (SELECT 1 FROM users WHERE email = ?)"; got != want {
+		t.Errorf("InsertIfNotExists mysql string: want %q, got %q", want, got)
+	}
+}
+
+func TestTruncateCascadeConfirm(t *testing.T) {
+	q := NewQuery("users")
+	s := q.Truncate(TruncateOptions{Cascade: true})
+
+	if got, want := s.String(), "TRUNCATE TABLE users"; got != want {
+		t.Errorf("Truncate cascade without confirm string: want %q, got %q", want, got)
+	}
+	if s.Err() == nil {
+		t.Errorf("Truncate cascade without confirm: want error")
+	}
+
+	s = q.ConfirmCascade().Truncate(TruncateOptions{Cascade: true})
+	if got, want := s.String(), "TRUNCATE TABLE users CASCADE"; got != want {
+		t.Errorf("Truncate cascade string: want %q, got %q", want, got)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("Truncate cascade error: want nil, got %v", err)
+	}
+
+	if q.Truncate(TruncateOptions{Cascade: true}).Err() == nil {
+		t.Errorf("Truncate cascade after confirmed truncate: want error")
+	}
+}
+
+func TestOrderByAlias(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{"pg", "SELECT user_id,SUM(amount) AS total FROM orders GROUP BY user_id ORDER BY SUM(amount) DESC,(SUM(amount)) / 2,user_id"},
+		{"mssql", "SELECT user_id,SUM(amount) AS total FROM orders GROUP BY user_id ORDER BY SUM(amount) DESC,(SUM(amount)) / 2,user_id"},
+		{"mysql", "SELECT user_id,SUM(amount) AS total FROM orders GROUP BY user_id ORDER BY total DESC,total / 2,user_id"},
+		{"sqlite", "SELECT user_id,SUM(amount) AS total FROM orders GROUP BY user_id ORDER BY total DESC,total / 2,user_id"},
+	}
+	for _, tt := range tests {
+		q := NewQuery("orders").SetDriver(tt.driver)
+		q.Select("user_id", "SUM(amount) AS total").GroupBy("user_id").OrderByAlias("total DESC", "total / 2", "user_id")
+		if got := q.String(); got != tt.want {
+			t.Errorf("OrderByAlias %s string: want %q, got %q", tt.driver, tt.want, got)
+		}
+	}
+
+	q := NewQuery("orders o")
+	q.Select("o.total AS amount").OrderByAlias("o.amount", "'amount'", "amount_id")
+	if got, want := q.String(), "SELECT o.total AS amount FROM orders o ORDER BY o.amount,'amount',amount_id"; got != want {
+		t.Errorf("OrderByAlias non alias string: want %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,151 @@
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// QueryReturning executes insert, update or delete statement with returning on db
+// and scans every returned row into dest, dest must be a pointer to
+// a slice of structs or pointers to structs.
+//
+// Columns are matched to fields by the "db" tag or the lowercased field name.
+// dest is left unchanged in dry run mode.
+//
+// QueryReturning panics if the driver doesn't support returning
+// or the statement has no returning.
+func (s *Statement) QueryReturning(ctx context.Context, db DB, dest interface{}) error {
+	s.checkReturning("sqlbuilder.QueryReturning")
+	slice := sliceDest("sqlbuilder.QueryReturning", dest)
+	rows, err := s.QueryContext(ctx, db)
+	if err != nil || rows == nil {
+		return err
+	}
+	return scanStructs(rows, slice)
+}
+
+// QueryReturningMap is like QueryReturning but returns every row as a map
+// of column names to values, e.g. for "DELETE ... RETURNING *".
+// rows are nil in dry run mode.
+//
+// QueryReturningMap panics if the driver doesn't support returning
+// or the statement has no returning.
+func (s *Statement) QueryReturningMap(ctx context.Context, db DB) ([]map[string]interface{}, error) {
+	s.checkReturning("sqlbuilder.QueryReturningMap")
+	rows, err := s.QueryContext(ctx, db)
+	if err != nil || rows == nil {
+		return nil, err
+	}
+	return ScanMap(rows)
+}
+
+// checkReturning panics if the driver doesn't support returning or statement has none.
+func (s *Statement) checkReturning(caller string) {
+	if !s.dialect().SupportsReturning() {
+		panic(caller + ": unsupported by driver: " + s.driver)
+	}
+	if s.returningAt < 0 {
+		panic(caller + ": statement has no returning")
+	}
+}
+
+// ScanMap scans every row into a map of column names to values and closes rows.
+func ScanMap(rows *sql.Rows) ([]map[string]interface{}, error) {
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(cols))
+	ptrs := make([]interface{}, len(cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	var result []map[string]interface{}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		m := make(map[string]interface{}, len(cols))
+		for i, col := range cols {
+			m[col] = values[i]
+		}
+		result = append(result, m)
+	}
+	return result, rows.Err()
+}
+
+// sliceDest returns the slice dest points to,
+// panics if dest is not a pointer to a slice of structs or pointers to structs.
+func sliceDest(caller string, dest interface{}) reflect.Value {
+	v := reflect.ValueOf(dest)
+	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Slice {
+		t := v.Elem().Type().Elem()
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			return v.Elem()
+		}
+	}
+	panic(caller + ": dest must be a pointer to a slice of structs")
+}
+
+// scanStructs scans rows into new elements appended to slice and closes rows.
+func scanStructs(rows *sql.Rows, slice reflect.Value) error {
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	fields := structFields(elemType)
+	index := make([][]int, len(cols))
+	for i, col := range cols {
+		idx, ok := fields[strings.ToLower(col)]
+		if !ok {
+			return fmt.Errorf("sqlbuilder: no field for column %q in %s", col, elemType)
+		}
+		index[i] = idx
+	}
+
+	ptrs := make([]interface{}, len(cols))
+	for rows.Next() {
+		elem := reflect.New(elemType)
+		for i, idx := range index {
+			ptrs[i] = elem.Elem().FieldByIndex(idx).Addr().Interface()
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		if !isPtr {
+			elem = elem.Elem()
+		}
+		slice.Set(reflect.Append(slice, elem))
+	}
+	return rows.Err()
+}
+
+// TrimPage removes the extra row fetched by WithHasMore from slice
+// pointed to by dest and reports whether there is a next page.
+func TrimPage(dest interface{}, perPage int) bool {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		panic("sqlbuilder.TrimPage: dest must be a pointer to a slice")
+	}
+	v = v.Elem()
+	if v.Len() <= perPage {
+		return false
+	}
+	v.Set(v.Slice(0, perPage))
+	return true
+}
@@ -0,0 +1,124 @@
+package sqlbuilder
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestQueryReturning(t *testing.T) {
+	type user struct {
+		ID     int64 `db:"id"`
+		Status string
+		secret string
+	}
+
+	f := &fakeDB{
+		cols: []string{"id", "status"},
+		rows: [][]driver.Value{{int64(1), "archived"}, {int64(2), "archived"}},
+	}
+	db := f.open()
+	defer db.Close()
+
+	var users []user
+	err := NewQuery("users").
+		Update("status = ?", "archived").
+		Where("last_login < ?", "2020-01-01").
+		Returning("id", "status").
+		QueryReturning(context.Background(), db, &users)
+	if err != nil {
+		t.Fatalf("QueryReturning: unexpected error: %v", err)
+	}
+
+	wantStr := "UPDATE users SET status = $1 WHERE last_login < $2 RETURNING id,status"
+	if f.query != wantStr {
+		t.Errorf("QueryReturning string: want %q, got %q", wantStr, f.query)
+	}
+	if len(users) != 2 {
+		t.Fatalf("QueryReturning rows length: want 2, got %d", len(users))
+	}
+	for i, u := range users {
+		if u.ID != int64(i+1) || u.Status != "archived" {
+			t.Errorf("QueryReturning rows[%d]: want {%d archived}, got %+v", i, i+1, u)
+		}
+	}
+
+	var ptrs []*user
+	err = NewQuery("users").Delete().Returning("id", "status").QueryReturning(context.Background(), db, &ptrs)
+	if err != nil || len(ptrs) != 2 || ptrs[1].ID != 2 {
+		t.Errorf("QueryReturning pointers: want 2 rows, got %v, %v", ptrs, err)
+	}
+}
+
+func TestQueryReturningPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *Statement
+		dest interface{}
+	}{
+		{"unsupported driver", NewQuery("users").SetDriver("mysql").Delete(), &[]struct{}{}},
+		{"no returning", NewQuery("users").Delete(), &[]struct{}{}},
+		{"dest not a slice", NewQuery("users").Delete().Returning("id"), &struct{}{}},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("QueryReturning %s: want panic", tt.name)
+				}
+			}()
+			tt.s.QueryReturning(context.Background(), nil, tt.dest)
+		}()
+	}
+}
+
+func TestQueryReturningMap(t *testing.T) {
+	f := &fakeDB{
+		cols: []string{"id", "name"},
+		rows: [][]driver.Value{{int64(1), "a"}, {int64(2), "b"}},
+	}
+	db := f.open()
+	defer db.Close()
+
+	rows, err := NewQuery("users").Delete().Where("active = ?", false).Returning().QueryReturningMap(context.Background(), db)
+	if err != nil {
+		t.Fatalf("QueryReturningMap: unexpected error: %v", err)
+	}
+
+	wantStr := "DELETE FROM users WHERE active = $1 RETURNING *"
+	if f.query != wantStr {
+		t.Errorf("QueryReturningMap string: want %q, got %q", wantStr, f.query)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("QueryReturningMap rows length: want 2, got %d", len(rows))
+	}
+	if rows[0]["id"] != int64(1) || rows[0]["name"] != "a" || rows[1]["id"] != int64(2) || rows[1]["name"] != "b" {
+		t.Errorf("QueryReturningMap rows: want [map[id:1 name:a] map[id:2 name:b]], got %v", rows)
+	}
+}
+
+func TestWithHasMore(t *testing.T) {
+	q := NewQuery("posts")
+	q.Select("id").OrderBy("id").WithHasMore(2)
+
+	if got, want := q.String(), "SELECT id FROM posts ORDER BY id LIMIT $1"; got != want {
+		t.Errorf("WithHasMore string: want %q, got %q", want, got)
+	}
+	if args := q.Args(); len(args) != 1 || args[0] != 3 {
+		t.Errorf("WithHasMore arguments: want [3], got %v", args)
+	}
+
+	ids := []int{1, 2, 3}
+	if !TrimPage(&ids, 2) {
+		t.Errorf("TrimPage full page: want true")
+	}
+	if len(ids) != 2 || ids[1] != 2 {
+		t.Errorf("TrimPage full page: want [1 2], got %v", ids)
+	}
+	if TrimPage(&ids, 2) {
+		t.Errorf("TrimPage last page: want false")
+	}
+	if len(ids) != 2 {
+		t.Errorf("TrimPage last page: want [1 2], got %v", ids)
+	}
+}
@@ -1,65 +1,1083 @@
 package sqlbuilder
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // Statement describes an sql query statement.
 type Statement struct {
 	*Query
 }
 
+// Must returns s, it panics if query has an error.
+func (s *Statement) Must() *Statement {
+	if s.err != nil {
+		panic(s.err)
+	}
+	return s
+}
+
+// WriteTo writes query string to w, it implements io.WriterTo.
+func (s *Statement) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, s.String())
+	return int64(n), err
+}
+
+// AppendQuery appends query string to dst and returns the extended buffer.
+func (s *Statement) AppendQuery(dst []byte) []byte {
+	dst = append(dst, s.str.String()...)
+	dst = append(dst, s.rowsCap()...)
+	if s.terminated() {
+		dst = append(dst, ';')
+	}
+	return dst
+}
+
+// PlaceholderCount returns the number of placeholders in query string,
+// placeholders inside quotes are not counted.
+func (s *Statement) PlaceholderCount() int {
+	var n int
+	replacePlaceholders(s.String(), s.dialect().prefix, func(int) string {
+		n++
+		return ""
+	})
+	return n
+}
+
+// HasWhere reports whether statement has a where clause.
+func (s *Statement) HasWhere() bool {
+	return s.clauses&clauseWhere != 0
+}
+
+// HasOrderBy reports whether statement has an order by clause.
+func (s *Statement) HasOrderBy() bool {
+	return s.clauses&clauseOrderBy != 0
+}
+
+// HasLimit reports whether statement has a limit clause.
+func (s *Statement) HasLimit() bool {
+	return s.clauses&clauseLimit != 0
+}
+
+// HasJoin reports whether statement has a join.
+func (s *Statement) HasJoin() bool {
+	return s.clauses&clauseJoin != 0
+}
+
+// writeClause writes str to query and marks statement as having clause c.
+func (s *Statement) writeClause(c clause, str string) {
+	if c == clauseWhere {
+		if !s.HasWhere() {
+			s.whereStart = s.str.Len()
+			s.whereArgs = len(s.args)
+		}
+		s.whereAt = s.str.Len() + len(" WHERE ")
+		s.orGroup = false
+	}
+	s.clauses |= c
+	s.str.WriteString(str)
+}
+
 // Where adds sql where condition to query.
-func (s *Statement) Where(cond string, args ...interface{}) *Statement {
-	s.str.WriteString(" WHERE ")
+// cond type can be string, Cond or func(*WhereBuilder).
+// args is only used if cond is a string.
+func (s *Statement) Where(cond interface{}, args ...interface{}) *Statement {
+	s.writeClause(clauseWhere, " WHERE ")
+	s.addCond("sqlbuilder.Where", cond, args...)
+	return s
+}
+
+// Not adds "NOT (cond)" sql where condition to query.
+func (s *Statement) Not(cond string, args ...interface{}) *Statement {
+	s.writeClause(clauseWhere, " WHERE NOT (")
 	s.Raw(cond, args...)
+	s.str.WriteByte(')')
+	return s
+}
+
+// ClearWhere removes the where clause and its arguments from query,
+// so a statement can be reused with other conditions.
+//
+// ClearWhere panics if the where clause is followed by other clauses.
+func (s *Statement) ClearWhere() *Statement {
+	if !s.HasWhere() {
+		return s
+	}
+	if s.clauses&clausesAfterWhere != 0 {
+		panic("sqlbuilder.ClearWhere: where must be the last clause")
+	}
+	s.truncate(s.whereStart)
+	s.args = s.args[:s.whereArgs]
+	s.clauses &^= clauseWhere
+	return s
+}
+
+// And adds cond to the where conditions of query joined by AND,
+// it's like Where if query has no where conditions.
+// cond type can be string, Cond or func(*WhereBuilder),
+// Cond groups are parenthesized, compound string conditions should be too.
+//
+// And panics if the where conditions are followed by other clauses.
+func (s *Statement) And(cond interface{}, args ...interface{}) *Statement {
+	if !s.HasWhere() {
+		return s.Where(cond, args...)
+	}
+	if s.clauses&clausesAfterWhere != 0 {
+		panic("sqlbuilder.And: must follow where conditions")
+	}
+	s.str.WriteString(" AND ")
+	s.addChainCond("sqlbuilder.And", cond, args...)
+	s.orGroup = false
+	return s
+}
+
+// Or adds cond to the where conditions of query joined by OR,
+// it's like Where if query has no where conditions.
+// The conditions joined by Or are parenthesized so a following And
+// applies to all of them, e.g. Where("a").Or("b").And("c") writes "(a OR b) AND c".
+// cond type is like in And.
+//
+// Or panics if the where conditions are followed by other clauses.
+func (s *Statement) Or(cond interface{}, args ...interface{}) *Statement {
+	if !s.HasWhere() {
+		return s.Where(cond, args...)
+	}
+	if s.clauses&clausesAfterWhere != 0 {
+		panic("sqlbuilder.Or: must follow where conditions")
+	}
+	prev := s.truncate(s.whereAt)
+	if s.orGroup {
+		s.str.WriteString(prev[:len(prev)-1])
+	} else {
+		s.str.WriteByte('(')
+		s.str.WriteString(prev)
+	}
+	s.str.WriteString(" OR ")
+	s.addChainCond("sqlbuilder.Or", cond, args...)
+	s.str.WriteByte(')')
+	s.orGroup = true
+	return s
+}
+
+// addChainCond writes cond like addCond with Cond groups parenthesized.
+func (s *Statement) addChainCond(caller string, cond interface{}, args ...interface{}) {
+	if fn, ok := cond.(func(*WhereBuilder)); ok {
+		var w WhereBuilder
+		fn(&w)
+		cond = w.Cond()
+	}
+	if c, ok := cond.(Cond); ok && c.isGroup() && len(c.conds) > 1 {
+		s.str.WriteByte('(')
+		c.apply(s.Query)
+		s.str.WriteByte(')')
+		return
+	}
+	s.addCond(caller, cond, args...)
+}
+
+// WhereOpAny adds "column op ANY(sub)" sql where condition to query.
+//
+// WhereOpAny panics if op is not a valid comparison operator.
+func (s *Statement) WhereOpAny(column, op string, sub *Statement) *Statement {
+	return s.whereOpSub("sqlbuilder.WhereOpAny", column, op, "ANY", sub)
+}
+
+// WhereOpAll adds "column op ALL(sub)" sql where condition to query.
+//
+// WhereOpAll panics if op is not a valid comparison operator.
+func (s *Statement) WhereOpAll(column, op string, sub *Statement) *Statement {
+	return s.whereOpSub("sqlbuilder.WhereOpAll", column, op, "ALL", sub)
+}
+
+func (s *Statement) whereOpSub(caller, column, op, quantifier string, sub *Statement) *Statement {
+	if !validOperator(op) {
+		panic(caller + ": invalid operator: " + op)
+	}
+	s.checkScalar(caller, sub)
+	s.writeClause(clauseWhere, " WHERE ")
+	s.str.WriteString(column)
+	s.str.WriteByte(' ')
+	s.str.WriteString(op)
+	s.str.WriteByte(' ')
+	s.str.WriteString(quantifier)
+	s.str.WriteByte('(')
+	s.embed(sub.Query)
+	s.str.WriteByte(')')
+	return s
+}
+
+// WhereIn adds "column IN (...)" sql where condition to query
+// with a placeholder per element of values,
+// values must be a slice or an array.
+// WhereIn with no values adds a condition that's always false.
+//
+// WhereIn panics if values is not a slice or an array.
+func (s *Statement) WhereIn(column string, values interface{}) *Statement {
+	switch vs := values.(type) {
+	case []int:
+		return s.WhereInInts(column, vs)
+	case []string:
+		return s.WhereInStrings(column, vs)
+	}
+	args := sliceArgs("sqlbuilder.WhereIn", values)
+	s.beginWhereIn(column, false, len(args))
+	for i, v := range args {
+		if i != 0 {
+			s.addComma()
+		}
+		s.addArg(v)
+	}
+	return s.endWhereIn(len(args))
+}
+
+// WhereInInts is like WhereIn for a slice of ints without using reflection.
+func (s *Statement) WhereInInts(column string, values []int) *Statement {
+	s.beginWhereIn(column, false, len(values))
+	for i, v := range values {
+		if i != 0 {
+			s.addComma()
+		}
+		s.addArg(v)
+	}
+	return s.endWhereIn(len(values))
+}
+
+// WhereInStrings is like WhereIn for a slice of strings without using reflection.
+func (s *Statement) WhereInStrings(column string, values []string) *Statement {
+	s.beginWhereIn(column, false, len(values))
+	for i, v := range values {
+		if i != 0 {
+			s.addComma()
+		}
+		s.addArg(v)
+	}
+	return s.endWhereIn(len(values))
+}
+
+// InValuesThreshold is the number of values above which WhereInValues
+// uses a VALUES list for pg.
+const InValuesThreshold = 100
+
+// WhereInValues is like WhereIn but for pg and more than InValuesThreshold values
+// it adds "column IN (VALUES (...),(...))", letting the planner join
+// the values list instead of checking a long IN list per row.
+//
+// WhereInValues panics if values is not a slice or an array.
+func (s *Statement) WhereInValues(column string, values interface{}) *Statement {
+	args := sliceArgs("sqlbuilder.WhereInValues", values)
+	if s.driver != "pg" || len(args) <= InValuesThreshold {
+		return s.WhereIn(column, args)
+	}
+	s.setSpecific()
+	s.writeClause(clauseWhere, " WHERE ")
+	s.str.WriteString(column)
+	s.str.WriteString(" IN (VALUES ")
+	for i, v := range args {
+		if i != 0 {
+			s.addComma()
+		}
+		s.str.WriteByte('(')
+		s.addArg(v)
+		s.str.WriteByte(')')
+	}
+	s.str.WriteByte(')')
+	return s
+}
+
+// WhereNotIn adds "column NOT IN (...)" sql where condition to query
+// with a placeholder per element of values,
+// values must be a slice or an array.
+// WhereNotIn with no values adds a condition that's always true.
+//
+// WhereNotIn panics if values is not a slice or an array.
+func (s *Statement) WhereNotIn(column string, values interface{}) *Statement {
+	args := sliceArgs("sqlbuilder.WhereNotIn", values)
+	s.beginWhereIn(column, true, len(args))
+	for i, v := range args {
+		if i != 0 {
+			s.addComma()
+		}
+		s.addArg(v)
+	}
+	return s.endWhereIn(len(args))
+}
+
+func (s *Statement) beginWhereIn(column string, not bool, n int) {
+	s.writeClause(clauseWhere, " WHERE ")
+	if n == 0 {
+		if not {
+			s.str.WriteString("1=1")
+		} else {
+			s.str.WriteString("1=0")
+		}
+		return
+	}
+	s.str.WriteString(column)
+	if not {
+		s.str.WriteString(" NOT IN (")
+	} else {
+		s.str.WriteString(" IN (")
+	}
+}
+
+func (s *Statement) endWhereIn(n int) *Statement {
+	if n != 0 {
+		s.str.WriteByte(')')
+	}
+	return s
+}
+
+// WhereDate adds sql where condition comparing the date part of column with date,
+// "column::date op $N" for pg and "DATE(column) op ?" for mysql.
+//
+// WhereDate panics if op is not a valid comparison operator.
+func (s *Statement) WhereDate(column, op string, date interface{}) *Statement {
+	if !validOperator(op) {
+		panic("sqlbuilder.WhereDate: invalid operator: " + op)
+	}
+	s.setSpecific()
+	s.writeClause(clauseWhere, " WHERE ")
+	switch s.driver {
+	case "pg":
+		s.str.WriteString(column)
+		s.str.WriteString("::date")
+	case "mssql":
+		s.str.WriteString("CAST(")
+		s.str.WriteString(column)
+		s.str.WriteString(" AS date)")
+	default:
+		s.str.WriteString("DATE(")
+		s.str.WriteString(column)
+		s.str.WriteByte(')')
+	}
+	s.str.WriteByte(' ')
+	s.str.WriteString(op)
+	s.str.WriteByte(' ')
+	s.addArg(date)
+	return s
+}
+
+// WhereExpr adds sql where condition "expr op value" to query,
+// e.g. WhereExpr("LOWER(email)", "=", email), value is bound as an argument.
+// expr is written as is and must be trusted.
+//
+// WhereExpr panics if op is not a valid comparison operator.
+func (s *Statement) WhereExpr(expr, op string, value interface{}) *Statement {
+	if !validOperator(op) {
+		panic("sqlbuilder.WhereExpr: invalid operator: " + op)
+	}
+	s.writeClause(clauseWhere, " WHERE ")
+	s.str.WriteString(expr)
+	s.str.WriteByte(' ')
+	s.str.WriteString(op)
+	s.str.WriteByte(' ')
+	s.addArg(value)
+	return s
+}
+
+// WhereDateRange adds sql where condition of half-open time range to query,
+// e.g. "column >= $1 AND column < $2", a zero from or to bound is skipped
+// and no condition is added if both are zero.
+func (s *Statement) WhereDateRange(column string, from, to time.Time) *Statement {
+	if !from.IsZero() {
+		s.writeClause(clauseWhere, " WHERE ")
+		s.str.WriteString(column)
+		s.str.WriteString(" >= ")
+		s.addArg(from)
+	}
+	if !to.IsZero() {
+		if from.IsZero() {
+			s.writeClause(clauseWhere, " WHERE ")
+		} else {
+			s.str.WriteString(" AND ")
+		}
+		s.str.WriteString(column)
+		s.str.WriteString(" < ")
+		s.addArg(to)
+	}
+	return s
+}
+
+// WhereJSONEq adds sql where condition that the value at path in json column equals value,
+// path is a dot separated list of object keys, e.g. "address.city".
+// It's "column #>> '{address,city}' = $1" for pg, "column->>'$.address.city' = ?" for mysql,
+// json_extract for sqlite and JSON_VALUE for mssql.
+// value is bound as an argument.
+//
+// WhereJSONEq panics if a path key is not a plain identifier.
+func (s *Statement) WhereJSONEq(column, path string, value interface{}) *Statement {
+	if !isPlainName(path) {
+		panic("sqlbuilder.WhereJSONEq: invalid path: " + path)
+	}
+	s.setSpecific()
+	s.writeClause(clauseWhere, " WHERE ")
+	switch s.driver {
+	case "pg":
+		s.str.WriteString(column)
+		s.str.WriteString(" #>> ")
+		s.str.WriteString(quoteString("{" + strings.ReplaceAll(path, ".", ",") + "}"))
+	case "mysql":
+		s.str.WriteString(column)
+		s.str.WriteString("->>")
+		s.str.WriteString(quoteString("$." + path))
+	case "mssql":
+		s.str.WriteString("JSON_VALUE(" + column + ", " + quoteString("$."+path) + ")")
+	default:
+		s.str.WriteString("json_extract(" + column + ", " + quoteString("$."+path) + ")")
+	}
+	s.str.WriteString(" = ")
+	s.addArg(value)
+	return s
+}
+
+// WhereTSVector adds "column @@ to_tsquery(config, query)" sql where condition,
+// column must be a tsvector, config is omitted if it's empty.
+// config and query are bound as arguments.
+//
+// WhereTSVector panics if driver is not pg.
+func (s *Statement) WhereTSVector(column, query, config string) *Statement {
+	if s.driver != "pg" {
+		panic("sqlbuilder.WhereTSVector: unsupported by driver: " + s.driver)
+	}
+	s.setSpecific()
+	s.writeClause(clauseWhere, " WHERE ")
+	s.str.WriteString(column)
+	s.str.WriteString(" @@ to_tsquery(")
+	if config != "" {
+		s.addArg(config)
+		s.addComma()
+	}
+	s.addArg(query)
+	s.str.WriteByte(')')
+	return s
+}
+
+// WhereTrue adds sql where condition that boolean column is true,
+// "column" for pg and "column = 1" for others.
+func (s *Statement) WhereTrue(column string) *Statement {
+	return s.whereBool(column, true)
+}
+
+// WhereFalse adds sql where condition that boolean column is false,
+// "NOT column" for pg and "column = 0" for others.
+func (s *Statement) WhereFalse(column string) *Statement {
+	return s.whereBool(column, false)
+}
+
+func (s *Statement) whereBool(column string, v bool) *Statement {
+	s.setSpecific()
+	s.writeClause(clauseWhere, " WHERE ")
+	if s.driver == "pg" {
+		if !v {
+			s.str.WriteString("NOT ")
+		}
+		s.str.WriteString(column)
+		return s
+	}
+	s.str.WriteString(column)
+	if v {
+		s.str.WriteString(" = 1")
+	} else {
+		s.str.WriteString(" = 0")
+	}
+	return s
+}
+
+// WhereBetweenCols adds "value BETWEEN lowCol AND highCol" sql where condition to query,
+// value is bound as an argument and columns are written as is.
+func (s *Statement) WhereBetweenCols(value interface{}, lowCol, highCol string) *Statement {
+	s.writeClause(clauseWhere, " WHERE ")
+	s.addArg(value)
+	s.str.WriteString(" BETWEEN ")
+	s.str.WriteString(lowCol)
+	s.str.WriteString(" AND ")
+	s.str.WriteString(highCol)
+	return s
+}
+
+// validOperator reports whether op is a valid sql comparison operator.
+func validOperator(op string) bool {
+	switch strings.ToUpper(op) {
+	case "=", "<>", "!=", "<", "<=", ">", ">=", "LIKE", "NOT LIKE", "ILIKE", "NOT ILIKE":
+		return true
+	}
+	return false
+}
+
+// Apply adds filter as sql where clause to query,
+// nothing is added if filter is empty.
+func (s *Statement) Apply(f *Filter) *Statement {
+	if f.Empty() {
+		return s
+	}
+	return s.Where(And(f.conds...))
+}
+
+// addCond writes cond to query, panics if cond type is unexpected.
+func (s *Statement) addCond(caller string, cond interface{}, args ...interface{}) {
+	switch c := cond.(type) {
+	case string:
+		s.Raw(c, args...)
+	case Cond:
+		c.apply(s.Query)
+	case func(*WhereBuilder):
+		var w WhereBuilder
+		c(&w)
+		w.Cond().apply(s.Query)
+	default:
+		panic(caller + ": unexpected cond type")
+	}
+}
+
+// Join adds sql inner join with on condition to query.
+func (s *Statement) Join(table, on string, args ...interface{}) *Statement {
+	return s.join(" JOIN ", table, on, args...)
+}
+
+// LeftJoin adds sql left join with on condition to query.
+func (s *Statement) LeftJoin(table, on string, args ...interface{}) *Statement {
+	return s.join(" LEFT JOIN ", table, on, args...)
+}
+
+// CrossJoin adds sql cross join to query.
+func (s *Statement) CrossJoin(table string) *Statement {
+	return s.join(" CROSS JOIN ", table, "")
+}
+
+// StraightJoin adds mysql straight join with on condition to query,
+// it forces the left table to be read before the right one.
+//
+// StraightJoin panics if driver is not mysql.
+func (s *Statement) StraightJoin(table, on string, args ...interface{}) *Statement {
+	if s.driver != "mysql" {
+		panic("sqlbuilder.StraightJoin: unsupported by driver: " + s.driver)
+	}
+	s.setSpecific()
+	return s.join(" STRAIGHT_JOIN ", table, on, args...)
+}
+
+// JoinSeriesLateral adds
+// "CROSS JOIN LATERAL generate_series(start,stop,step) AS alias(t)"
+// to query, start, stop and step are bound as arguments.
+//
+// JoinSeriesLateral panics if driver is not pg.
+func (s *Statement) JoinSeriesLateral(start, stop, step interface{}, alias string) *Statement {
+	if s.driver != "pg" {
+		panic("sqlbuilder.JoinSeriesLateral: unsupported by driver: " + s.driver)
+	}
+	s.setSpecific()
+	s.writeClause(clauseJoin, " CROSS JOIN LATERAL generate_series(")
+	s.addArg(start)
+	s.addComma()
+	s.addArg(stop)
+	s.addComma()
+	s.addArg(step)
+	s.str.WriteString(") AS ")
+	s.str.WriteString(alias)
+	s.str.WriteString("(t)")
+	return s
+}
+
+// CrossApply adds mssql "CROSS APPLY (sub) alias" to query,
+// the sql server equivalent of a lateral join.
+// sub's arguments are appended to query arguments.
+//
+// CrossApply panics if driver is not mssql.
+func (s *Statement) CrossApply(sub *Statement, alias string) *Statement {
+	return s.applyJoin("sqlbuilder.CrossApply", " CROSS APPLY (", sub, alias)
+}
+
+// OuterApply is like CrossApply for "OUTER APPLY (sub) alias",
+// rows without a match in sub are kept.
+//
+// OuterApply panics if driver is not mssql.
+func (s *Statement) OuterApply(sub *Statement, alias string) *Statement {
+	return s.applyJoin("sqlbuilder.OuterApply", " OUTER APPLY (", sub, alias)
+}
+
+func (s *Statement) applyJoin(caller, typ string, sub *Statement, alias string) *Statement {
+	if s.driver != "mssql" {
+		panic(caller + ": unsupported by driver: " + s.driver)
+	}
+	s.setSpecific()
+	s.writeClause(clauseJoin, typ)
+	s.embed(sub.Query)
+	s.str.WriteString(") ")
+	s.str.WriteString(alias)
+	return s
+}
+
+func (s *Statement) join(typ, table, on string, args ...interface{}) *Statement {
+	s.writeClause(clauseJoin, typ)
+	s.str.WriteString(table)
+	if on != "" {
+		s.str.WriteString(" ON ")
+		s.Raw(on, args...)
+	}
+	return s
+}
+
+// Union adds sql union with other statement to query,
+// other's arguments are appended to query arguments.
+// Both statements are parenthesized except for sqlite,
+// e.g. "(SELECT ...) UNION (SELECT ...)", so their own order by and limit
+// stay with them and order by and limit added after Union apply to the union.
+func (s *Statement) Union(other *Statement) *Statement {
+	return s.union(" UNION ", other)
+}
+
+// UnionAll is like Union for sql union all.
+func (s *Statement) UnionAll(other *Statement) *Statement {
+	return s.union(" UNION ALL ", other)
+}
+
+func (s *Statement) union(op string, other *Statement) *Statement {
+	paren := s.driver != "sqlite"
+	if paren && s.clauses&clauseUnion == 0 {
+		first := s.truncate(s.stmtAt)
+		s.str.WriteByte('(')
+		s.str.WriteString(first)
+		s.str.WriteByte(')')
+	}
+	s.clauses = s.clauses&^(clauseOrderBy|clauseLimit|clauseOffset) | clauseUnion
+	s.str.WriteString(op)
+	// the rows cap applies to the whole union, not to its operands.
+	if paren {
+		s.str.WriteByte('(')
+		s.embedString(other.Query, other.str.String())
+		s.str.WriteByte(')')
+	} else {
+		s.embedString(other.Query, other.str.String())
+	}
+	return s
+}
+
+// GroupBy adds sql group by columns to query.
+func (s *Statement) GroupBy(columns ...string) *Statement {
+	if len(columns) > 0 {
+		s.writeClause(clauseGroupBy, " GROUP BY ")
+		s.addColumns(columns...)
+	}
+	return s
+}
+
+// GroupByRollup adds sql group by columns with subtotal rows to query,
+// "GROUP BY ROLLUP(a,b)" or "GROUP BY a,b WITH ROLLUP" for mysql.
+//
+// GroupByRollup panics if columns is empty or driver is sqlite.
+func (s *Statement) GroupByRollup(columns ...string) *Statement {
+	if len(columns) == 0 {
+		panic("sqlbuilder.GroupByRollup: columns cannot be empty")
+	}
+	if s.driver == "sqlite" {
+		panic("sqlbuilder.GroupByRollup: unsupported by driver: " + s.driver)
+	}
+	s.setSpecific()
+	if s.driver == "mysql" {
+		s.writeClause(clauseGroupBy, " GROUP BY ")
+		s.addColumns(columns...)
+		s.str.WriteString(" WITH ROLLUP")
+		return s
+	}
+	s.writeClause(clauseGroupBy, " GROUP BY ROLLUP(")
+	s.addColumns(columns...)
+	s.str.WriteByte(')')
+	return s
+}
+
+// GroupByPos adds sql group by select list positions to query.
+//
+// GroupByPos panics if a position is < 1.
+func (s *Statement) GroupByPos(positions ...int) *Statement {
+	if len(positions) > 0 {
+		s.writeClause(clauseGroupBy, " GROUP BY ")
+		for i, p := range positions {
+			if p < 1 {
+				panic("sqlbuilder: invalid group by position")
+			}
+			s.str.WriteString(strconv.Itoa(p))
+			if i != len(positions)-1 {
+				s.addComma()
+			}
+		}
+	}
+	return s
+}
+
+// Having adds sql having condition to query.
+// cond type can be string, Cond or func(*WhereBuilder).
+// args is only used if cond is a string.
+func (s *Statement) Having(cond interface{}, args ...interface{}) *Statement {
+	s.writeClause(clauseHaving, " HAVING ")
+	s.addCond("sqlbuilder.Having", cond, args...)
 	return s
 }
 
 // Limit adds sql limit to query.
+// It's "OFFSET 0 ROWS FETCH NEXT n ROWS ONLY" for mssql, which requires an order by,
+// "ORDER BY (SELECT NULL)" is written if statement has none.
+// Limit in update and delete statements is only supported by mysql.
 //
-// Limit panics if n <= 0.
+// Limit panics if n <= 0, if the driver doesn't support it
+// or if it follows Offset with mysql or sqlite.
 func (s *Statement) Limit(n int) *Statement {
 	if n <= 0 {
 		panic("sqlbuilder: invalid limit value")
 	}
-	s.str.WriteString(" LIMIT ")
+	if s.maxRows > 0 && n > s.maxRows {
+		n = s.maxRows
+	}
+	return s.limit("sqlbuilder.Limit", n)
+}
+
+func (s *Statement) limit(caller string, n int) *Statement {
+	s.checkOrderLimit(caller)
+	s.checkLimitOffset(caller)
+	s.addDefaultOrderBy()
+	if s.driver == "mssql" {
+		s.addFetch(n)
+		return s
+	}
+	s.writeClause(clauseLimit, " LIMIT ")
 	s.addArg(n)
 	return s
 }
 
+// addFetch writes mssql limit "FETCH NEXT n ROWS ONLY",
+// preceded by "OFFSET 0 ROWS" if statement has no offset.
+func (s *Statement) addFetch(n int) {
+	s.setSpecific()
+	if s.clauses&clauseOffset == 0 {
+		s.addOrderByNull()
+		s.limitAt = s.str.Len()
+		s.writeClause(clauseLimit, " OFFSET 0 ROWS FETCH NEXT ")
+	} else {
+		s.writeClause(clauseLimit, " FETCH NEXT ")
+	}
+	s.addArg(n)
+	s.str.WriteString(" ROWS ONLY")
+}
+
+// addRowsCap writes the rows cap of statement as its limit clause,
+// so clauses that must follow the limit can be written.
+func (s *Statement) addRowsCap() {
+	if c := s.rowsCap(); c != "" {
+		s.writeClause(clauseLimit, c)
+	}
+}
+
+// WithHasMore adds sql limit of perPage+1 to query, the extra row
+// reports whether there is a next page, see TrimPage.
+// The extra row isn't capped by SetMaxRows.
+//
+// WithHasMore sets query error if perPage exceeds max rows.
+func (s *Statement) WithHasMore(perPage int) *Statement {
+	if perPage <= 0 {
+		panic("sqlbuilder.WithHasMore: invalid per page value")
+	}
+	if s.maxRows > 0 && perPage > s.maxRows {
+		s.setErr(fmt.Errorf("sqlbuilder.WithHasMore: per page %d exceeds max rows %d", perPage, s.maxRows))
+		perPage = s.maxRows
+	}
+	return s.limit("sqlbuilder.WithHasMore", perPage+1)
+}
+
+// LimitAll adds sql "LIMIT ALL" to query.
+//
+// LimitAll panics if driver is not pg.
+func (s *Statement) LimitAll() *Statement {
+	if s.driver != "pg" {
+		panic("sqlbuilder.LimitAll: unsupported by driver: " + s.driver)
+	}
+	s.checkOrderLimit("sqlbuilder.LimitAll")
+	s.setSpecific()
+	s.writeClause(clauseLimit, " LIMIT ALL")
+	return s
+}
+
+// LimitSub adds sql limit of the value of scalar sub query to query,
+// e.g. "LIMIT (SELECT n FROM config)".
+//
+// LimitSub sets query error if sub selects more than one column.
+// LimitSub panics if driver is not pg or sqlite or if it follows Offset with sqlite.
+func (s *Statement) LimitSub(sub *Statement) *Statement {
+	if s.driver != "pg" && s.driver != "sqlite" {
+		panic("sqlbuilder.LimitSub: unsupported by driver: " + s.driver)
+	}
+	s.checkOrderLimit("sqlbuilder.LimitSub")
+	s.checkLimitOffset("sqlbuilder.LimitSub")
+	s.checkScalar("sqlbuilder.LimitSub", sub)
+	s.setSpecific()
+	s.addDefaultOrderBy()
+	s.writeClause(clauseLimit, " LIMIT (")
+	s.embed(sub.Query)
+	s.str.WriteByte(')')
+	return s
+}
+
 // Offset adds sql offset to query.
+// Offset without Limit is written after the rows cap set by SetMaxRows
+// or the limit clause the driver requires,
+// "LIMIT -1" for sqlite and "LIMIT 18446744073709551615" for mysql.
+// It's "OFFSET n ROWS" for mssql, which requires an order by,
+// "ORDER BY (SELECT NULL)" is written if statement has none,
+// and it's written in place of "OFFSET 0 ROWS" written by Limit.
 //
 // Offset panics if n <= 0.
 func (s *Statement) Offset(n int) *Statement {
 	if n <= 0 {
 		panic("sqlbuilder: invalid offset value")
 	}
-	s.str.WriteString(" OFFSET ")
+	s.addDefaultOrderBy()
+	if !s.HasLimit() || s.driver == "mssql" {
+		s.setSpecific()
+	}
+	switch s.driver {
+	case "mssql":
+		var fetch string
+		if s.HasLimit() {
+			fetch = strings.TrimPrefix(s.truncate(s.limitAt), " OFFSET 0 ROWS")
+		}
+		s.addOrderByNull()
+		s.writeClause(clauseOffset, " OFFSET ")
+		s.addArg(n)
+		s.str.WriteString(" ROWS")
+		s.str.WriteString(fetch)
+		return s
+	case "sqlite", "mysql":
+		// the rows cap must precede the offset.
+		s.addRowsCap()
+		if s.HasLimit() {
+			break
+		}
+		if s.driver == "sqlite" {
+			s.writeClause(clauseLimit, " LIMIT -1")
+		} else {
+			s.writeClause(clauseLimit, " LIMIT 18446744073709551615")
+		}
+	}
+	s.writeClause(clauseOffset, " OFFSET ")
 	s.addArg(n)
 	return s
 }
 
+// addOrderByNull adds "ORDER BY (SELECT NULL)" to statement that has no order by,
+// mssql requires one for OFFSET and FETCH.
+func (s *Statement) addOrderByNull() {
+	if !s.HasOrderBy() {
+		s.writeClause(clauseOrderBy, " ORDER BY (SELECT NULL)")
+	}
+}
+
 // OrderBy adds sql order by columns asc to query.
+// OrderBy in update and delete statements is only supported by mysql.
+//
+// OrderBy panics if the driver doesn't support it.
 func (s *Statement) OrderBy(columns ...string) *Statement {
 	if len(columns) > 0 {
-		s.str.WriteString(" ORDER BY ")
+		s.checkOrderLimit("sqlbuilder.OrderBy")
+		s.writeClause(clauseOrderBy, " ORDER BY ")
 		s.addColumns(columns...)
 	}
 	return s
 }
 
+// OrderByAlias adds sql order by columns to query,
+// columns can reference aliases of the select list, e.g. "total * 2 DESC".
+// pg and mssql don't allow aliases in order by expressions,
+// so the aliased select expression is written in place of the alias.
+func (s *Statement) OrderByAlias(columns ...string) *Statement {
+	if s.driver != "pg" && s.driver != "mssql" {
+		return s.OrderBy(columns...)
+	}
+	aliases := s.selectAliases()
+	if len(aliases) == 0 {
+		return s.OrderBy(columns...)
+	}
+	resolved := make([]string, len(columns))
+	for i, c := range columns {
+		resolved[i] = resolveAliases(c, aliases)
+	}
+	return s.OrderBy(resolved...)
+}
+
+// selectAliases returns the expressions of select list columns
+// aliased with "expr AS alias" by their alias.
+func (s *Statement) selectAliases() map[string]string {
+	var aliases map[string]string
+	for _, c := range s.selectList {
+		i := strings.LastIndex(strings.ToUpper(c), " AS ")
+		if i < 0 {
+			continue
+		}
+		if alias := strings.TrimSpace(c[i+len(" AS "):]); isPlainIdent(alias) {
+			if aliases == nil {
+				aliases = make(map[string]string)
+			}
+			aliases[alias] = strings.TrimSpace(c[:i])
+		}
+	}
+	return aliases
+}
+
+// resolveAliases returns column with aliases replaced by their expressions,
+// an expression is wrapped in parentheses unless it's the whole column
+// optionally followed by a direction, e.g. "total DESC".
+// Quoted strings and qualified names are left as is.
+func resolveAliases(column string, aliases map[string]string) string {
+	f := strings.Fields(column)
+	if len(f) == 0 {
+		return column
+	}
+	if expr, ok := aliases[f[0]]; ok && (len(f) == 1 || len(f) == 2 && isDirection(f[1])) {
+		return strings.Replace(column, f[0], expr, 1)
+	}
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(column); i++ {
+		c := column[i]
+		switch {
+		case c == '\'' || c == '"':
+			if j := strings.IndexByte(column[i+1:], c); j != -1 {
+				i += j + 1
+			}
+		case isIdentByte(c, true) && (i == 0 || !isIdentByte(column[i-1], false) && column[i-1] != '.'):
+			j := i + 1
+			for j < len(column) && isIdentByte(column[j], false) {
+				j++
+			}
+			if expr, ok := aliases[column[i:j]]; ok && (j == len(column) || column[j] != '.' && column[j] != '(') {
+				b.WriteString(column[last:i])
+				b.WriteByte('(')
+				b.WriteString(expr)
+				b.WriteByte(')')
+				last = j
+			}
+			i = j - 1
+		}
+	}
+	b.WriteString(column[last:])
+	return b.String()
+}
+
+func isDirection(s string) bool {
+	switch strings.ToUpper(s) {
+	case "ASC", "DESC":
+		return true
+	}
+	return false
+}
+
 // OrderByDesc adds sql order by columns desc to query.
+// OrderByDesc in update and delete statements is only supported by mysql.
+//
+// OrderByDesc panics if the driver doesn't support it.
 func (s *Statement) OrderByDesc(columns ...string) *Statement {
 	if len(columns) > 0 {
-		s.str.WriteString(" ORDER BY ")
+		s.checkOrderLimit("sqlbuilder.OrderByDesc")
+		s.writeClause(clauseOrderBy, " ORDER BY ")
 		s.addColumns(columns...)
 		s.str.WriteString(" DESC")
 	}
 	return s
 }
 
+// Order describes an sql order by column and its direction.
+type Order struct {
+	Column string
+	Desc   bool
+}
+
+// OrderByPairs adds sql order by columns with their directions to query,
+// e.g. "ORDER BY a ASC,b DESC".
+// OrderByPairs in update and delete statements is only supported by mysql.
+//
+// OrderByPairs panics if the driver doesn't support it.
+func (s *Statement) OrderByPairs(orders ...Order) *Statement {
+	if len(orders) > 0 {
+		s.checkOrderLimit("sqlbuilder.OrderByPairs")
+		s.writeClause(clauseOrderBy, " ORDER BY ")
+		for i, o := range orders {
+			if i != 0 {
+				s.addComma()
+			}
+			s.str.WriteString(o.Column)
+			if o.Desc {
+				s.str.WriteString(" DESC")
+			} else {
+				s.str.WriteString(" ASC")
+			}
+		}
+	}
+	return s
+}
+
+// OrderByUserInput adds sql order by column with direction to query
+// from untrusted input, e.g. api sort parameters.
+// column must be one of the columns set by SetOrderByColumns
+// and dir one of "ASC", "DESC", "asc" or "desc",
+// otherwise query error is set and nothing is added.
+func (s *Statement) OrderByUserInput(column, dir string) *Statement {
+	if !s.orderColumns[column] {
+		s.setErr(fmt.Errorf("sqlbuilder.OrderByUserInput: column not allowed: %q", column))
+		return s
+	}
+	switch dir {
+	case "ASC", "asc":
+		return s.OrderByPairs(Order{Column: column})
+	case "DESC", "desc":
+		return s.OrderByPairs(Order{Column: column, Desc: true})
+	}
+	s.setErr(fmt.Errorf("sqlbuilder.OrderByUserInput: invalid direction: %q", dir))
+	return s
+}
+
+// addDefaultOrderBy adds the order by set by SetDefaultOrderBy
+// to select statement that has none.
+func (s *Statement) addDefaultOrderBy() {
+	if s.kind == KindSelect && len(s.defaultOrderBy) > 0 && !s.HasOrderBy() {
+		s.OrderBy(s.defaultOrderBy...)
+	}
+}
+
+// checkOrderLimit panics if statement is update or delete and driver is not mysql.
+func (s *Statement) checkOrderLimit(caller string) {
+	switch s.kind {
+	case KindUpdate, KindDelete:
+		if s.driver != "mysql" {
+			panic(caller + ": unsupported in update and delete statements by driver: " + s.driver)
+		}
+	}
+}
+
+// checkLimitOffset panics if statement has an offset and driver
+// requires limit to precede it.
+func (s *Statement) checkLimitOffset(caller string) {
+	if s.clauses&clauseOffset != 0 && (s.driver == "mysql" || s.driver == "sqlite") {
+		panic(caller + ": must precede Offset with driver: " + s.driver)
+	}
+}
+
 // Returning adds sql returning to query.
-// Should be used with insert or update.
+// Should be used with insert, update or delete.
+// columns are written as is, so they can be expressions, e.g. As("now() - created_at", "age").
+// Returning with no columns adds "RETURNING *".
+//
+// Returning panics if the driver doesn't support it.
 func (s *Statement) Returning(columns ...string) *Statement {
-	if len(columns) > 0 {
-		s.str.WriteString(" RETURNING ")
+	if !s.dialect().SupportsReturning() {
+		panic("sqlbuilder.Returning: unsupported by driver: " + s.driver)
+	}
+	s.setSpecific()
+	s.returningAt = s.str.Len()
+	s.writeClause(clauseReturning, " RETURNING ")
+	if len(columns) == 0 {
+		s.str.WriteByte('*')
+	} else {
 		s.addColumns(columns...)
 	}
 	return s
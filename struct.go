@@ -0,0 +1,136 @@
+package sqlbuilder
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// InsertStruct returns sql insert statement of record's columns and values.
+// Columns are the "db" tag or the lowercased field name of exported fields,
+// fields tagged "-" are skipped.
+// If query has no tables the table is derived from record, see structTable.
+//
+// InsertStruct panics if record is not a struct or a pointer to a struct.
+func (q *Query) InsertStruct(record interface{}) *Statement {
+	cols, values := structValues("sqlbuilder.InsertStruct", record)
+	defer q.useStructTable(record)()
+	return q.insertRow(cols, values)
+}
+
+// UpdateStruct returns sql update statement setting record's columns to its values,
+// columns and table are derived like in InsertStruct.
+//
+// UpdateStruct panics if record is not a struct or a pointer to a struct.
+func (q *Query) UpdateStruct(record interface{}) *Statement {
+	cols, values := structValues("sqlbuilder.UpdateStruct", record)
+	defer q.useStructTable(record)()
+	q.begin(KindUpdate)
+	q.str.WriteString("UPDATE ")
+	q.addTables()
+	q.str.WriteString(" SET ")
+	for i, c := range cols {
+		if i != 0 {
+			q.addComma()
+		}
+		q.addAssign(c, values[i])
+	}
+	return q.Statement()
+}
+
+// tableNamer is implemented by structs that name their table.
+type tableNamer interface {
+	TableName() string
+}
+
+// useStructTable sets query table to the table of record if query has no tables,
+// it returns a function restoring query tables.
+func (q *Query) useStructTable(record interface{}) func() {
+	if len(q.tables) != 0 {
+		return func() {}
+	}
+	q.tables = []string{structTable(record)}
+	return func() { q.tables = nil }
+}
+
+// structTable returns the result of record's TableName method
+// or its snake cased type name, e.g. "user_profile" for UserProfile.
+func structTable(record interface{}) string {
+	if t, ok := record.(tableNamer); ok {
+		return t.TableName()
+	}
+	t := reflect.TypeOf(record)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return snakeCase(t.Name())
+}
+
+// snakeCase returns s converted from CamelCase to snake_case,
+// acronyms are kept together, e.g. "http_log" for HTTPLog.
+func snakeCase(s string) string {
+	r := []rune(s)
+	var b strings.Builder
+	for i, c := range r {
+		if unicode.IsUpper(c) {
+			if i > 0 && (unicode.IsLower(r[i-1]) || unicode.IsDigit(r[i-1]) ||
+				i+1 < len(r) && unicode.IsLower(r[i+1]) && unicode.IsUpper(r[i-1])) {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// structFields returns index of exported fields of t by their column name,
+// see fieldColumn.
+func structFields(t reflect.Type) map[string][]int {
+	fields := make(map[string][]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if name := fieldColumn(f); name != "" {
+			fields[name] = f.Index
+		}
+	}
+	return fields
+}
+
+// fieldColumn returns column name of f, the "db" tag or the lowercased field name,
+// it's empty for unexported fields and fields tagged "-".
+func fieldColumn(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+	name := f.Tag.Get("db")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return strings.ToLower(name)
+}
+
+// structValues returns columns and values of exported fields of record in field order,
+// panics if record is not a struct or a pointer to a struct.
+func structValues(caller string, record interface{}) ([]string, []interface{}) {
+	v := reflect.ValueOf(record)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		panic(caller + ": record must be a struct or a pointer to a struct")
+	}
+	t := v.Type()
+	cols := make([]string, 0, t.NumField())
+	values := make([]interface{}, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name := fieldColumn(t.Field(i)); name != "" {
+			cols = append(cols, name)
+			values = append(values, v.Field(i).Interface())
+		}
+	}
+	return cols, values
+}
@@ -0,0 +1,153 @@
+package sqlbuilder
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+type account struct {
+	ID    int64 `db:"id"`
+	Email string
+}
+
+func (account) TableName() string { return "accounts" }
+
+type UserProfile struct {
+	UserID int64 `db:"user_id"`
+	Bio    string
+}
+
+func TestInsertStruct(t *testing.T) {
+	q := NewQuery()
+	q.InsertStruct(&account{ID: 1, Email: "a@b.c"})
+
+	gotStr := q.String()
+	wantStr := "INSERT INTO accounts(id,email) VALUES ($1,$2)"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{int64(1), "a@b.c"}
+
+	if gotStr != wantStr {
+		t.Errorf("InsertStruct string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("InsertStruct arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("InsertStruct arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+	if len(q.Tables()) != 0 {
+		t.Errorf("InsertStruct tables: want none, got %v", q.Tables())
+	}
+
+	if got, want := NewQuery("users").InsertStruct(account{}).String(), "INSERT INTO users(id,email) VALUES ($1,$2)"; got != want {
+		t.Errorf("InsertStruct with table string: want %q, got %q", want, got)
+	}
+}
+
+func TestUpdateStruct(t *testing.T) {
+	q := NewQuery()
+	q.UpdateStruct(UserProfile{UserID: 7, Bio: "hi"}).Where("user_id = ?", 7)
+
+	gotStr := q.String()
+	wantStr := "UPDATE user_profile SET user_id=$1,bio=$2 WHERE user_id = $3"
+	gotArgs := q.Args()
+	wantArgs := []interface{}{int64(7), "hi", 7}
+
+	if gotStr != wantStr {
+		t.Errorf("UpdateStruct string: want %q, got %q", wantStr, gotStr)
+	}
+	if len(gotArgs) != len(wantArgs) {
+		t.Errorf("UpdateStruct arguments length: want %d, got %d", len(wantArgs), len(gotArgs))
+	}
+	for i, v := range gotArgs {
+		if v != wantArgs[i] {
+			t.Errorf("UpdateStruct arguments[%d]: want %v, got %v", i, wantArgs[i], v)
+		}
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{"User", "user"},
+		{"UserProfile", "user_profile"},
+		{"HTTPLog", "http_log"},
+		{"Order2Item", "order2_item"},
+	}
+	for _, tt := range tests {
+		if got := snakeCase(tt.s); got != tt.want {
+			t.Errorf("snakeCase(%q): want %q, got %q", tt.s, tt.want, got)
+		}
+	}
+}
+
+type orderStatus string
+
+type orderPriority int8
+
+type upperName string
+
+func (n upperName) Value() (driver.Value, error) {
+	return strings.ToUpper(string(n)), nil
+}
+
+func TestInsertStructNamedTypes(t *testing.T) {
+	type Order struct {
+		ID       int64
+		Status   orderStatus
+		Priority orderPriority
+		Owner    upperName
+		Data     []byte
+	}
+
+	q := NewQuery("orders")
+	q.InsertStruct(Order{ID: 1, Status: "paid", Priority: 2, Owner: "bob", Data: []byte("{}")})
+
+	gotArgs := q.Args()
+	wantArgs := []interface{}{int64(1), "paid", int8(2), upperName("bob")}
+
+	if len(gotArgs) != len(wantArgs)+1 {
+		t.Fatalf("InsertStruct named types arguments length: want %d, got %d", len(wantArgs)+1, len(gotArgs))
+	}
+	for i, v := range wantArgs {
+		if gotArgs[i] != v {
+			t.Errorf("InsertStruct named types arguments[%d]: want %T(%v), got %T(%v)", i, v, v, gotArgs[i], gotArgs[i])
+		}
+	}
+
+	q.Insert([]string{"data", "status"}, []byte("{}"), orderStatus("new"))
+	if got, want := q.String(), "INSERT INTO orders(data,status) VALUES ($1,$2)"; got != want {
+		t.Errorf("Insert bytes value string: want %q, got %q", want, got)
+	}
+	if v := q.Arg(2); v != "new" {
+		t.Errorf("Insert named string argument: want string(new), got %T(%v)", v, v)
+	}
+}
+
+func TestInsertStructSliceField(t *testing.T) {
+	type Post struct {
+		Tags []string
+		Name string
+	}
+
+	q := NewQuery("posts")
+	q.InsertStruct(Post{Tags: []string{"a", "b"}, Name: "x"})
+
+	if got, want := q.String(), "INSERT INTO posts(tags,name) VALUES ($1,$2)"; got != want {
+		t.Errorf("InsertStruct slice field string: want %q, got %q", want, got)
+	}
+	args := q.Args()
+	if len(args) != 2 {
+		t.Fatalf("InsertStruct slice field arguments length: want 2, got %d", len(args))
+	}
+	if tags, ok := args[0].([]string); !ok || len(tags) != 2 {
+		t.Errorf("InsertStruct slice field arguments[0]: want [a b], got %v", args[0])
+	}
+	if args[1] != "x" {
+		t.Errorf("InsertStruct slice field arguments[1]: want x, got %v", args[1])
+	}
+}
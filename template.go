@@ -0,0 +1,32 @@
+package sqlbuilder
+
+import "strconv"
+
+// Template describes a statement built once and executed with different arguments.
+//
+// The arguments the statement was built with only mark the slots,
+// Bind returns the cached sql with a new set of arguments.
+type Template struct {
+	sql   string
+	nargs int
+}
+
+// Template returns Template of query string with a slot per query argument.
+func (q *Query) Template() *Template {
+	return &Template{sql: q.String(), nargs: len(q.args)}
+}
+
+// SQL returns template sql.
+func (t *Template) SQL() string {
+	return t.sql
+}
+
+// Bind returns template sql and args to execute it with.
+//
+// Bind panics if the number of args doesn't match the template slots.
+func (t *Template) Bind(args ...interface{}) (string, []interface{}) {
+	if len(args) != t.nargs {
+		panic("sqlbuilder.Bind: want " + strconv.Itoa(t.nargs) + " arguments, got " + strconv.Itoa(len(args)))
+	}
+	return t.sql, args
+}
@@ -0,0 +1,32 @@
+package sqlbuilder
+
+import "testing"
+
+func TestTemplate(t *testing.T) {
+	tpl := NewQuery("users").Select("id", "name").Where("id = ? AND active = ?", nil, nil).Template()
+
+	sql1, args1 := tpl.Bind(1, true)
+	sql2, args2 := tpl.Bind(2, false)
+
+	wantStr := "SELECT id,name FROM users WHERE id = $1 AND active = $2"
+
+	if sql1 != wantStr {
+		t.Errorf("Template string: want %q, got %q", wantStr, sql1)
+	}
+	if sql1 != sql2 || sql1 != tpl.SQL() {
+		t.Errorf("Template string: want the same sql for every Bind, got %q and %q", sql1, sql2)
+	}
+	if len(args1) != 2 || args1[0] != 1 || args1[1] != true {
+		t.Errorf("Template arguments: want [1 true], got %v", args1)
+	}
+	if len(args2) != 2 || args2[0] != 2 || args2[1] != false {
+		t.Errorf("Template arguments: want [2 false], got %v", args2)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Bind with wrong number of arguments: want panic")
+		}
+	}()
+	tpl.Bind(1)
+}
@@ -0,0 +1,55 @@
+package sqlbuilder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Window describes a window of a window function call.
+type Window struct {
+	PartitionBy []string
+	OrderBy     []string
+
+	// Distinct makes an aggregate "fn(DISTINCT arg)",
+	// none of the supported drivers allow it in a window function.
+	Distinct bool
+}
+
+// Over returns "fn(arg) OVER (PARTITION BY ... ORDER BY ...)" window function expression,
+// e.g. Over("COUNT", "*", Window{PartitionBy: []string{"user_id"}}).
+//
+// Over sets query error if w is Distinct, it's unsupported by every driver.
+// The error is also set on the next statement built with query,
+// so Over can be passed to the statement's Select.
+func (q *Query) Over(fn, arg string, w Window) string {
+	var b strings.Builder
+	b.WriteString(fn)
+	b.WriteByte('(')
+	if w.Distinct {
+		q.setExprErr(fmt.Errorf("sqlbuilder.Over: DISTINCT in window function unsupported by driver: %s", q.driver))
+		b.WriteString("DISTINCT ")
+	}
+	b.WriteString(arg)
+	b.WriteString(") OVER (")
+	if len(w.PartitionBy) > 0 {
+		b.WriteString("PARTITION BY ")
+		b.WriteString(strings.Join(w.PartitionBy, ","))
+	}
+	if len(w.OrderBy) > 0 {
+		if len(w.PartitionBy) > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString("ORDER BY ")
+		b.WriteString(strings.Join(w.OrderBy, ","))
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// WithTotalWindow makes the next select statement add
+// "COUNT(*) OVER() AS total" to its columns, so a page of rows
+// is returned with the total number of rows matching the query before limit.
+func (q *Query) WithTotalWindow() *Query {
+	q.pending.totalWindow = true
+	return q
+}
@@ -0,0 +1,52 @@
+package sqlbuilder
+
+import "testing"
+
+func TestOver(t *testing.T) {
+	q := NewQuery("orders")
+	q.Select("id", q.Over("SUM", "total", Window{PartitionBy: []string{"user_id"}, OrderBy: []string{"created_at"}}), q.Over("COUNT", "*", Window{}))
+
+	gotStr := q.String()
+	wantStr := "SELECT id,SUM(total) OVER (PARTITION BY user_id ORDER BY created_at),COUNT(*) OVER () FROM orders"
+
+	if gotStr != wantStr {
+		t.Errorf("Over string: want %q, got %q", wantStr, gotStr)
+	}
+	if err := q.Err(); err != nil {
+		t.Errorf("Over: unexpected error: %v", err)
+	}
+}
+
+func TestOverDistinct(t *testing.T) {
+	q := NewQuery("orders")
+	q.Select(q.Over("COUNT", "user_id", Window{PartitionBy: []string{"shop_id"}, Distinct: true}))
+
+	if q.Err() == nil {
+		t.Errorf("Over Distinct with pg driver: want error")
+	}
+
+	s := NewQuery("orders").Select("shop_id")
+	s.OrderBy(s.Over("COUNT", "user_id", Window{Distinct: true}))
+
+	if s.Err() == nil {
+		t.Errorf("Over Distinct in order by with pg driver: want error")
+	}
+	if err := s.Select("id").Err(); err != nil {
+		t.Errorf("Select after Over Distinct in order by: unexpected error: %v", err)
+	}
+}
+
+func TestWithTotalWindow(t *testing.T) {
+	q := NewQuery("posts")
+	q.WithTotalWindow().Select().Where("user_id = ?", 7).OrderBy("id").Limit(20)
+
+	gotStr := q.String()
+	wantStr := "SELECT *,COUNT(*) OVER() AS total FROM posts WHERE user_id = $1 ORDER BY id LIMIT $2"
+
+	if gotStr != wantStr {
+		t.Errorf("WithTotalWindow string: want %q, got %q", wantStr, gotStr)
+	}
+	if got, want := q.Select("id").String(), "SELECT id FROM posts"; got != want {
+		t.Errorf("Select after WithTotalWindow string: want %q, got %q", want, got)
+	}
+}